@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"portkiller/internal/config"
+)
+
+// command describes a subcommand for dispatch, usage, shell completion and
+// the man page.
+type command struct {
+	name string
+	// synopsis follows the command name in usage lines.
+	synopsis string
+	summary  string
+	// flags returns a fresh flag set describing the command's flags; nil
+	// for commands without any.
+	flags func() *flag.FlagSet
+	// subcommands are the fixed words accepted as the first argument.
+	subcommands []string
+	// complete suggests positional arguments.
+	complete func(cfg config.Config) []candidate
+	// early commands run before the config is loaded, so they work with a
+	// broken config file.
+	early bool
+	run   func(cfg config.Config, path string, args []string) error
+}
+
+// commandTree lists pzapp's subcommands in the order usage shows them.
+func commandTree() []command {
+	return []command{
+		{
+			name:     "kill",
+			synopsis: "[--signal SIG] [--force] --pid N | PORT",
+			summary:  "terminate a process, or every process on PORT (run under sudo by the TUI when needed)",
+			flags:    func() *flag.FlagSet { return killFlags(new(killOptions)) },
+			complete: portCandidates,
+			run: func(cfg config.Config, _ string, args []string) error {
+				return runKill(cfg, args)
+			},
+		},
+		{
+			name:     "run",
+			synopsis: "--port N [--on-conflict ask|kill|next|abort] -- cmd [args...]",
+			summary:  "free port N (kill its holder or pick the next one), then exec cmd with PORT set",
+			flags:    func() *flag.FlagSet { return runFlags(new(runOptions)) },
+			run: func(cfg config.Config, _ string, args []string) error {
+				return runRun(cfg, args)
+			},
+		},
+		{
+			name:     "serve",
+			synopsis: "[--listen ADDR] [--token-file FILE] [--read-only]",
+			summary:  "serve the port list, kills and Prometheus metrics over HTTP (default 127.0.0.1:7777)",
+			flags:    func() *flag.FlagSet { return serveFlags(new(serveOptions)) },
+			run: func(cfg config.Config, _ string, args []string) error {
+				return runServe(cfg, args)
+			},
+		},
+		{
+			name:        "config",
+			synopsis:    "print|path",
+			summary:     "show the effective merged configuration, or the config file location",
+			subcommands: []string{"print", "path"},
+			run:         runConfig,
+		},
+		{
+			name:        "completion",
+			synopsis:    "bash|zsh|fish",
+			summary:     "print a shell completion script",
+			subcommands: completionShells,
+			early:       true,
+			run: func(_ config.Config, _ string, args []string) error {
+				return runCompletion(args)
+			},
+		},
+		{
+			name:    "man",
+			summary: "print the man page in roff format",
+			early:   true,
+			run: func(_ config.Config, _ string, args []string) error {
+				return runMan(args)
+			},
+		},
+	}
+}
+
+// findCommand returns the subcommand called name.
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commandTree() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// globalOptions are the flags accepted before the command.
+type globalOptions struct {
+	configPath   string
+	theme        string
+	reduceMotion bool
+	host         string
+}
+
+func globalFlags(opts *globalOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("pzapp", flag.ContinueOnError)
+	fs.StringVar(&opts.configPath, "config", "", "path to the config file (default $XDG_CONFIG_HOME/pzapp/config.toml)")
+	fs.StringVar(&opts.theme, "theme", "", "colour theme: matrix, tokyonight, high-contrast, monochrome or a [themes] entry")
+	fs.BoolVar(&opts.reduceMotion, "reduce-motion", false, "disable accent cycling, matrix rain and glitch effects (implied by NO_COLOR)")
+	fs.StringVar(&opts.host, "host", "", "inspect and kill on this host over ssh: host, user@host or an ssh_config alias")
+	return fs
+}
+
+// printUsage writes the top-level usage: commands from the tree, then the
+// global flags.
+func printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintf(w, "Usage: pzapp [flags] [command]\n\nCommands:\n  (none)         start the interactive port list\n")
+	for _, cmd := range commandTree() {
+		writeCommandUsage(w, cmd)
+	}
+	fmt.Fprintf(w, "\nFlags:\n")
+	fs.PrintDefaults()
+}
+
+func writeCommandUsage(w io.Writer, cmd command) {
+	line := strings.TrimSpace(cmd.name + " " + cmd.synopsis)
+	if len(line) > 13 {
+		fmt.Fprintf(w, "  %s\n  %-13s  %s\n", line, "", cmd.summary)
+		return
+	}
+	fmt.Fprintf(w, "  %-13s  %s\n", line, cmd.summary)
+}
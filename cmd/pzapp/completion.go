@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"portkiller/internal/config"
+	"portkiller/internal/ports"
+)
+
+// completeCommand is the hidden command the completion scripts call. It
+// takes the words after `pzapp` up to and including the one being
+// completed, and prints one candidate per line, optionally followed by a tab
+// and a description. No output tells the shell to complete file names.
+const completeCommand = "__complete"
+
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// candidate is one completion suggestion.
+type candidate struct {
+	value       string
+	description string
+}
+
+// flagValues suggests values for flags, by flag name. Flags taking a value
+// that are missing here complete file names.
+var flagValues = map[string]func(cfg config.Config) []candidate{
+	"pid":         pidCandidates,
+	"port":        portCandidates,
+	"signal":      func(config.Config) []candidate { return plain(ports.Signals()) },
+	"on-conflict": func(config.Config) []candidate { return plain(conflictActions) },
+	"theme":       themeCandidates,
+	"listen":      func(config.Config) []candidate { return plain([]string{"127.0.0.1:7777", "unix:"}) },
+	"host":        hostCandidates,
+}
+
+// runCompletion implements `pzapp completion SHELL`.
+func runCompletion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("completion: want one of %s", strings.Join(completionShells, ", "))
+	}
+	script, ok := map[string]string{"bash": bashCompletion, "zsh": zshCompletion, "fish": fishCompletion}[args[0]]
+	if !ok {
+		return fmt.Errorf("completion: unknown shell %q (want one of %s)", args[0], strings.Join(completionShells, ", "))
+	}
+	_, err := io.WriteString(os.Stdout, script)
+	return err
+}
+
+// runComplete prints the candidates for the last of words.
+func runComplete(w io.Writer, words []string) error {
+	for _, c := range complete(words) {
+		if c.description == "" {
+			fmt.Fprintln(w, c.value)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\n", c.value, c.description)
+	}
+	return nil
+}
+
+// complete walks words the way run would parse them, then suggests flags,
+// flag values, commands or positional arguments for the last word.
+func complete(words []string) []candidate {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current, before := words[len(words)-1], words[:len(words)-1]
+
+	var opts globalOptions
+	fs := globalFlags(&opts)
+	var cmd *command
+	positional := 0
+	pending := "" // a flag whose value is the next word
+	for _, word := range before {
+		switch {
+		case pending != "":
+			switch pending {
+			case "config":
+				opts.configPath = word
+			case "host":
+				opts.host = word
+			}
+			pending = ""
+		case word == "--":
+			// Everything after `run --` belongs to the command being run.
+			return nil
+		case strings.HasPrefix(word, "-") && word != "-":
+			if name, ok := takesValue(fs, word); ok {
+				pending = name
+			}
+		case cmd == nil:
+			found, ok := findCommand(word)
+			if !ok {
+				return nil
+			}
+			cmd = &found
+			fs = commandFlags(found)
+		default:
+			positional++
+		}
+	}
+
+	// Values are completed with the config the command line points at, so
+	// providers and custom themes match what the command would see.
+	cfg := completionConfig(opts.configPath)
+	if opts.host != "" {
+		cfg.Host = opts.host
+	}
+
+	var candidates []candidate
+	prefix := current
+	switch {
+	case pending != "":
+		candidates = flagValueCandidates(pending, cfg)
+	case strings.HasPrefix(current, "-"):
+		if name, value, ok := strings.Cut(strings.TrimLeft(current, "-"), "="); ok {
+			values := flagValueCandidates(name, cfg)
+			for i := range values {
+				values[i].value = current[:len(current)-len(value)] + values[i].value
+			}
+			candidates = values
+			break
+		}
+		candidates = flagCandidates(fs)
+	case cmd == nil:
+		for _, c := range commandTree() {
+			candidates = append(candidates, candidate{value: c.name, description: c.summary})
+		}
+	case len(cmd.subcommands) > 0:
+		if positional == 0 {
+			candidates = plain(cmd.subcommands)
+		}
+	case cmd.complete != nil:
+		candidates = cmd.complete(cfg)
+	}
+
+	var matches []candidate
+	for _, c := range candidates {
+		if strings.HasPrefix(c.value, prefix) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// commandFlags returns cmd's flag set, or an empty one.
+func commandFlags(cmd command) *flag.FlagSet {
+	if cmd.flags == nil {
+		return flag.NewFlagSet("pzapp "+cmd.name, flag.ContinueOnError)
+	}
+	return cmd.flags()
+}
+
+// takesValue reports whether word is a flag of fs whose value is the next
+// word, and returns its name.
+func takesValue(fs *flag.FlagSet, word string) (string, bool) {
+	name := strings.TrimLeft(word, "-")
+	if strings.Contains(name, "=") {
+		return "", false
+	}
+	f := fs.Lookup(name)
+	if f == nil || isBoolFlag(f) {
+		return "", false
+	}
+	return name, true
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func flagCandidates(fs *flag.FlagSet) []candidate {
+	var candidates []candidate
+	fs.VisitAll(func(f *flag.Flag) {
+		candidates = append(candidates, candidate{value: "--" + f.Name, description: f.Usage})
+	})
+	return candidates
+}
+
+// flagValueCandidates suggests values for the flag called name.
+func flagValueCandidates(name string, cfg config.Config) []candidate {
+	if suggest, ok := flagValues[name]; ok {
+		return suggest(cfg)
+	}
+	return nil
+}
+
+func plain(values []string) []candidate {
+	candidates := make([]candidate, len(values))
+	for i, v := range values {
+		candidates[i] = candidate{value: v}
+	}
+	return candidates
+}
+
+// completionConfig loads the config for value completion, falling back to
+// the defaults: a broken config should not break the shell.
+func completionConfig(flagPath string) config.Config {
+	path, err := configPath(flagPath)
+	if err != nil {
+		return config.Default()
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return config.Default()
+	}
+	return cfg
+}
+
+// listForCompletion lists ports without fingerprinting, which would probe
+// every port on each keypress.
+func listForCompletion(cfg config.Config) []ports.Port {
+	cfg.Fingerprint.Enabled = false
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ListTimeout)
+	defer cancel()
+	entries, err := provider.List(ctx)
+	if err != nil {
+		return nil
+	}
+	return entries
+}
+
+// portCandidates suggests the listening ports, described by their owners.
+func portCandidates(cfg config.Config) []candidate {
+	owners := make(map[int][]string)
+	for _, entry := range listForCompletion(cfg) {
+		owner := entry.Process
+		if !entry.Hidden {
+			owner = fmt.Sprintf("%s (PID %d)", entry.Process, entry.PID)
+		}
+		if !containsString(owners[entry.Port], owner) {
+			owners[entry.Port] = append(owners[entry.Port], owner)
+		}
+	}
+	return sortedCandidates(owners)
+}
+
+// pidCandidates suggests the PIDs of listening processes, described by
+// their name and ports.
+func pidCandidates(cfg config.Config) []candidate {
+	names := make(map[int]string)
+	listening := make(map[int][]string)
+	for _, entry := range listForCompletion(cfg) {
+		if entry.Hidden {
+			continue
+		}
+		names[entry.PID] = entry.Process
+		port := ":" + strconv.Itoa(entry.Port)
+		if !containsString(listening[entry.PID], port) {
+			listening[entry.PID] = append(listening[entry.PID], port)
+		}
+	}
+	for pid, portList := range listening {
+		listening[pid] = []string{names[pid] + " " + strings.Join(portList, " ")}
+	}
+	return sortedCandidates(listening)
+}
+
+func sortedCandidates(described map[int][]string) []candidate {
+	keys := make([]int, 0, len(described))
+	for key := range described {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	candidates := make([]candidate, len(keys))
+	for i, key := range keys {
+		candidates[i] = candidate{value: strconv.Itoa(key), description: strings.Join(described[key], ", ")}
+	}
+	return candidates
+}
+
+// hostCandidates suggests the ssh targets of the configured hosts.
+func hostCandidates(cfg config.Config) []candidate {
+	candidates := make([]candidate, len(cfg.Hosts))
+	for i, h := range cfg.Hosts {
+		candidates[i] = candidate{value: h.Target}
+	}
+	return candidates
+}
+
+func themeCandidates(cfg config.Config) []candidate {
+	names := append([]string(nil), config.BuiltinThemes...)
+	for name := range cfg.Themes {
+		if !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[len(config.BuiltinThemes):])
+	return plain(names)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// The scripts pass the words up to the cursor to `pzapp __complete` and use
+// its output, falling back to file names when there is none.
+
+const bashCompletion = `# bash completion for pzapp; load with: source <(pzapp completion bash)
+_pzapp() {
+    local line=${COMP_LINE:0:COMP_POINT} words out
+    read -ra words <<< "$line"
+    [[ $line == *[[:space:]] ]] && words+=("")
+    out=$("${words[0]}" __complete "${words[@]:1}" 2>/dev/null)
+    if [[ -z $out ]]; then
+        compopt -o default 2>/dev/null
+        COMPREPLY=()
+        return
+    fi
+    # bash splits --flag=value at the "=", so only the value is replaced.
+    local cur=${words[${#words[@]}-1]} strip=""
+    [[ $cur == -*=* ]] && strip=${cur%%=*}=
+    local IFS=$'\n' c
+    COMPREPLY=()
+    for c in $out; do
+        c=${c%%$'\t'*}
+        COMPREPLY+=("${c#"$strip"}")
+    done
+}
+complete -F _pzapp pzapp
+`
+
+const zshCompletion = `#compdef pzapp
+# zsh completion for pzapp; load with: source <(pzapp completion zsh)
+# or save as _pzapp in a directory on $fpath.
+_pzapp() {
+    local -a lines completions
+    local line value
+    lines=("${(@f)$(${words[1]} __complete "${(@)words[2,$CURRENT]}" 2>/dev/null)}")
+    for line in $lines; do
+        [[ -z $line ]] && continue
+        value=${line%%$'\t'*}
+        value=${value//:/\\:}
+        if [[ $line == *$'\t'* ]]; then
+            completions+=("$value:${line#*$'\t'}")
+        else
+            completions+=("$value")
+        fi
+    done
+    if (( ${#completions} == 0 )); then
+        _files
+        return
+    fi
+    _describe -V pzapp completions
+}
+
+if [[ $funcstack[1] == _pzapp ]]; then
+    _pzapp "$@"
+else
+    compdef _pzapp pzapp
+fi
+`
+
+const fishCompletion = `# fish completion for pzapp; load with: pzapp completion fish | source
+function __pzapp_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    set -l out (command $tokens[1] __complete $tokens[2..-1] 2>/dev/null)
+    if test (count $out) -eq 0
+        __fish_complete_path (commandline -ct)
+        return
+    end
+    printf '%s\n' $out
+end
+complete -c pzapp -f -a '(__pzapp_complete)'
+`
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	t.Setenv("PZAPP_USE_MOCK", "1")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cases := []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{"ki"}, []string{"kill"}},
+		{[]string{"kill", "30"}, []string{"3000"}},
+		{[]string{"kill", "--pid", "45"}, []string{"4521"}},
+		{[]string{"kill", "--sig"}, []string{"--signal"}},
+		{[]string{"kill", "--signal", "K"}, []string{"KILL"}},
+		{[]string{"kill", "--signal=US"}, []string{"--signal=USR1", "--signal=USR2"}},
+		{[]string{"--reduce-motion", "--theme", "t"}, []string{"tokyonight"}},
+		{[]string{"config", ""}, []string{"print", "path"}},
+		{[]string{"config", "print", ""}, nil},
+		{[]string{"run", "--port", "3000", "--", "np"}, nil},
+		{[]string{"--config", ""}, nil},
+		{[]string{"bogus", ""}, nil},
+	}
+	for _, c := range cases {
+		var got []string
+		for _, candidate := range complete(c.words) {
+			got = append(got, candidate.value)
+		}
+		if strings.Join(got, " ") != strings.Join(c.want, " ") {
+			t.Errorf("complete(%q) = %q, want %q", c.words, got, c.want)
+		}
+	}
+}
+
+func TestCompleteDescribesPorts(t *testing.T) {
+	t.Setenv("PZAPP_USE_MOCK", "1")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var out bytes.Buffer
+	runComplete(&out, []string{"kill", "543"})
+	if got := out.String(); got != "5432\tpostgres (PID 9112)\n" {
+		t.Fatalf("runComplete output = %q", got)
+	}
+}
+
+func TestManPageIsUpToDate(t *testing.T) {
+	want, err := os.ReadFile("../../docs/pzapp.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	writeMan(&got)
+	if got.String() != string(want) {
+		t.Fatal("docs/pzapp.1 is stale; regenerate it with go generate ./cmd/pzapp")
+	}
+}
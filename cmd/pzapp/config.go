@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"portkiller/internal/config"
+)
+
+func runConfig(cfg config.Config, path string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("config: missing subcommand (want print or path)")
+	}
+
+	switch args[0] {
+	case "print":
+		fmt.Printf("# effective configuration (file: %s)\n", path)
+		return config.Encode(os.Stdout, cfg)
+	case "path":
+		fmt.Println(path)
+		return nil
+	default:
+		return fmt.Errorf("config: unknown subcommand %q (want print or path)", args[0])
+	}
+}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package main
+
+import "syscall"
+
+// execCommand replaces pzapp with argv, so the command owns the terminal
+// and its exit status is the one the shell sees.
+func execCommand(argv, env []string) error {
+	path, err := lookCommand(argv)
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(path, argv, env)
+}
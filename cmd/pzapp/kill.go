@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+
+	"portkiller/internal/audit"
+	"portkiller/internal/config"
+	"portkiller/internal/killer"
+	"portkiller/internal/ports"
+)
+
+// killOptions are the flags of `pzapp kill`.
+type killOptions struct {
+	pid         int
+	signal      string
+	force       bool
+	historyFile string
+	auditFile   string
+}
+
+func killFlags(opts *killOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("pzapp kill", flag.ContinueOnError)
+	fs.IntVar(&opts.pid, "pid", 0, "process to terminate")
+	fs.StringVar(&opts.signal, "signal", "", "first signal to send (default from the [kill] config)")
+	fs.BoolVar(&opts.force, "force", false, "kill processes whose protection rule asks for confirmation")
+	fs.StringVar(&opts.historyFile, "history-file", "", "record the kill in this history file (the TUI passes its own under sudo)")
+	fs.StringVar(&opts.auditFile, "audit-file", "", "write the audit record to this file when the file sink is enabled")
+	return fs
+}
+
+// runKill implements `pzapp kill --pid N` and `pzapp kill PORT`. It applies
+// the same protection rules, history and audit log as the TUI, which runs
+// it under sudo when a kill is not permitted.
+func runKill(cfg config.Config, args []string) error {
+	var opts killOptions
+	fs := killFlags(&opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	port := 0
+	if fs.NArg() == 1 && opts.pid == 0 {
+		var err error
+		if port, err = strconv.Atoi(fs.Arg(0)); err != nil || port <= 0 || port > 65535 {
+			return fmt.Errorf("kill: invalid port %q", fs.Arg(0))
+		}
+	} else if opts.pid <= 0 || fs.NArg() > 0 {
+		fs.Usage()
+		return fmt.Errorf("kill: --pid or a port is required")
+	}
+	if opts.signal != "" {
+		name, err := ports.ParseSignal(opts.signal)
+		if err != nil {
+			return fmt.Errorf("kill: %w", err)
+		}
+		cfg.Kill.Signal = name
+	}
+	if opts.historyFile != "" {
+		cfg.History.Path = opts.historyFile
+	}
+	if opts.auditFile != "" {
+		cfg.Audit.Path = opts.auditFile
+	}
+
+	var targets []ports.Port
+	if port != 0 {
+		var err error
+		if targets, err = portTargets(cfg, port); err != nil {
+			return fmt.Errorf("kill: %w", err)
+		}
+	} else {
+		target, err := findTarget(cfg, opts.pid)
+		if err != nil {
+			return fmt.Errorf("kill: %w", err)
+		}
+		targets = []ports.Port{target}
+	}
+
+	auditor, err := openAudit(cfg, audit.SourceCLI)
+	if err != nil {
+		return err
+	}
+	defer auditor.Close()
+	k, err := killer.New(cfg, auditor)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		result := k.Kill(target, opts.force)
+		if result.HistoryErr != nil {
+			log.Printf("pzapp: recording kill history: %v", result.HistoryErr)
+		}
+		if result.AuditErr != nil {
+			log.Printf("pzapp: writing audit log: %v", result.AuditErr)
+		}
+		if result.Err != nil {
+			return result.Err
+		}
+		fmt.Printf("Terminated %s (PID %d)\n", target.Process, target.PID)
+	}
+	return nil
+}
+
+// portTargets returns one entry per process listening on port, with either
+// protocol, on this machine's network namespace.
+func portTargets(cfg config.Config, port int) ([]ports.Port, error) {
+	cfg.Fingerprint.Enabled = false
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ListTimeout)
+	defer cancel()
+	entries, err := provider.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := killTargets(entries, port)
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("nothing is listening on port %d", port)
+	}
+	return targets, nil
+}
+
+// killTargets picks one entry per process on port from entries. Sockets in
+// other network namespaces only share the port number with it, so they are
+// left to `pzapp kill --pid`.
+func killTargets(entries []ports.Port, port int) []ports.Port {
+	var targets []ports.Port
+	seen := make(map[int]bool)
+	for _, entry := range entries {
+		if entry.Port != port || !entry.Reachable() || (seen[entry.PID] && !entry.Hidden) {
+			continue
+		}
+		seen[entry.PID] = true
+		targets = append(targets, entry)
+	}
+	return targets
+}
+
+// findTarget describes pid for protection checks and the audit log, using
+// its first listening socket when it has one.
+func findTarget(cfg config.Config, pid int) (ports.Port, error) {
+	cfg.Fingerprint.Enabled = false
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return ports.Port{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ListTimeout)
+	defer cancel()
+	if entries, err := provider.List(ctx); err == nil {
+		for _, entry := range entries {
+			if entry.PID == pid {
+				return entry, nil
+			}
+		}
+	}
+
+	// Not listening, or the listing failed: describe the process from its
+	// executable so name-based rules still apply. Remote executables are
+	// out of reach, so only PID rules apply there.
+	target := []ports.Port{{PID: pid}}
+	if cfg.Host != "" {
+		target[0].Host = cfg.SSH.Remote(cfg.Host).Name()
+	} else if exe := ports.ExePath(target[0]); exe != "" {
+		target[0].Process = filepath.Base(exe)
+	}
+	ports.Protect(cfg.Protection.RuleSet(), target)
+	return target[0], nil
+}
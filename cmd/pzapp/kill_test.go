@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"portkiller/internal/ports"
+)
+
+func TestKillTargetsSkipsOtherNamespaces(t *testing.T) {
+	entries := []ports.Port{
+		{PID: 10, Process: "node", Protocol: "TCP", Port: 8080, Address: "*"},
+		{PID: 10, Process: "node", Protocol: "TCP", Port: 8080, Address: "::"},
+		{PID: 20, Process: "dnsmasq", Protocol: "UDP", Port: 8080, Address: "*"},
+		{PID: 30, Process: "nginx", Protocol: "TCP", Port: 8080, Address: "*", Container: "docker:3f2a1b9c4d5e", NetNS: "net:[4026532451]"},
+		{PID: 40, Process: "vite", Protocol: "TCP", Port: 5173, Address: "*"},
+	}
+	var pids []int
+	for _, target := range killTargets(entries, 8080) {
+		pids = append(pids, target.PID)
+	}
+	if len(pids) != 2 || pids[0] != 10 || pids[1] != 20 {
+		t.Fatalf("killTargets(8080) picked PIDs %v, want [10 20]", pids)
+	}
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 
+	"portkiller/internal/audit"
+	"portkiller/internal/config"
+	"portkiller/internal/killer"
 	"portkiller/internal/ports"
 	"portkiller/internal/ui"
 
@@ -11,16 +17,240 @@ import (
 )
 
 func main() {
-	var provider ports.Provider
-	if os.Getenv("PZAPP_USE_MOCK") == "1" {
-		provider = ports.NewMockProvider()
-	} else {
-		provider = ports.NewSystemProvider()
+	log.SetFlags(0)
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		log.Fatalf("pzapp: %v", err)
+	}
+}
+
+func run(args []string) error {
+	// The shells call __complete with whatever is on the command line, so
+	// it cannot go through flag parsing.
+	if len(args) > 0 && args[0] == completeCommand {
+		return runComplete(os.Stdout, args[1:])
+	}
+
+	var opts globalOptions
+	fs := globalFlags(&opts)
+	fs.Usage = func() { printUsage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var cmd command
+	if name := fs.Arg(0); name != "" {
+		var ok bool
+		if cmd, ok = findCommand(name); !ok {
+			fs.Usage()
+			return fmt.Errorf("unknown command %q", name)
+		}
+		if cmd.early {
+			return cmd.run(config.Config{}, "", fs.Args()[1:])
+		}
+	}
+
+	path, err := configPath(opts.configPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := applyFlags(&cfg, opts); err != nil {
+		return err
+	}
+
+	if cmd.run == nil {
+		return runTUI(cfg, path)
+	}
+	return cmd.run(cfg, path, fs.Args()[1:])
+}
+
+// configPath returns the config file to load: the --config flag, or the
+// default location.
+func configPath(flagPath string) (string, error) {
+	if flagPath != "" {
+		return flagPath, nil
+	}
+	return config.Path()
+}
+
+// applyFlags layers command-line overrides and NO_COLOR (https://no-color.org)
+// on top of the loaded configuration.
+func applyFlags(cfg *config.Config, opts globalOptions) error {
+	noColor := os.Getenv("NO_COLOR") != ""
+	switch {
+	case opts.theme != "":
+		cfg.Theme = opts.theme
+	case noColor:
+		cfg.Theme = "monochrome"
+	}
+	if opts.reduceMotion || noColor {
+		cfg.ReduceMotion = true
+	}
+	if opts.host != "" {
+		cfg.Host = opts.host
+	}
+	cfg.Normalize()
+	return cfg.Validate()
+}
+
+func runTUI(cfg config.Config, path string) error {
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return err
+	}
+
+	auditor, err := openAudit(cfg, audit.SourceTUI)
+	if err != nil {
+		return err
+	}
+	defer auditor.Close()
+	k, err := killer.New(cfg, auditor)
+	if err != nil {
+		return err
 	}
 
-	program := tea.NewProgram(ui.New(provider))
+	var opts []tea.ProgramOption
+	if cfg.Mouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+	helper, err := sudoKillCommand(cfg, path)
+	if err != nil {
+		return err
+	}
+	model := ui.New(provider, cfg).WithKiller(k).WithSudoKill(helper)
+	if cfg.Host != "" {
+		model = model.WithHost(cfg.SSH.Remote(cfg.Host).Name())
+	} else if len(cfg.Hosts) > 0 {
+		hosts, err := remoteHosts(cfg)
+		if err != nil {
+			return err
+		}
+		model = model.WithHosts(hosts)
+	}
+	program := tea.NewProgram(model, opts...)
 
 	if err := program.Start(); err != nil {
-		log.Fatalf("failed to start pzapp: %v", err)
+		return fmt.Errorf("failed to start pzapp: %w", err)
+	}
+	return nil
+}
+
+// remoteHosts builds a tab of the host switcher for each configured host.
+func remoteHosts(cfg config.Config) ([]ui.Host, error) {
+	hosts := make([]ui.Host, 0, len(cfg.Hosts))
+	for _, h := range cfg.Hosts {
+		hostCfg := cfg.ForHost(h)
+		provider, err := newProvider(hostCfg)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, ui.Host{
+			Name:            cfg.SSH.Remote(h.Target).Name(),
+			Provider:        provider,
+			RefreshInterval: hostCfg.RefreshInterval,
+			ListTimeout:     hostCfg.ListTimeout,
+		})
+	}
+	return hosts, nil
+}
+
+// sudoKillCommand is how the TUI re-invokes pzapp for `kill` under sudo.
+// The config, history and audit paths are passed explicitly since sudo may
+// reset $HOME and $XDG_*, which would send the helper's records to root's
+// state directory instead of the user's.
+func sudoKillCommand(cfg config.Config, path string) ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = os.Args[0]
+	}
+	helper := []string{exe, "--config", path, "kill"}
+	if cfg.History.Enabled {
+		historyPath, err := cfg.HistoryPath()
+		if err != nil {
+			return nil, err
+		}
+		helper = append(helper, "--history-file", historyPath)
+	}
+	if cfg.Audit.Enabled && cfg.Audit.Sink == "file" {
+		auditPath, err := auditFile(cfg)
+		if err != nil {
+			return nil, err
+		}
+		helper = append(helper, "--audit-file", auditPath)
+	}
+	return helper, nil
+}
+
+// auditFile returns the file the audit file sink writes to.
+func auditFile(cfg config.Config) (string, error) {
+	if cfg.Audit.Path != "" {
+		return cfg.Audit.Path, nil
+	}
+	return audit.DefaultPath()
+}
+
+// openAudit opens the configured audit sink, or returns a nil Logger when
+// auditing is off. A sink that cannot be opened is fatal: on a shared host an
+// unaudited kill is worse than no kill.
+func openAudit(cfg config.Config, source string) (*audit.Logger, error) {
+	if !cfg.Audit.Enabled {
+		return nil, nil
+	}
+
+	var sink audit.Sink
+	var err error
+	switch cfg.Audit.Sink {
+	case "syslog":
+		sink, err = audit.OpenSyslog()
+	default:
+		var path string
+		if path, err = auditFile(cfg); err != nil {
+			return nil, err
+		}
+		sink, err = audit.OpenFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	return audit.New(sink, source), nil
+}
+
+// newProvider builds the configured provider. PZAPP_USE_MOCK=1 still forces
+// the mock provider for demos. The system provider also reports hidden
+// sockets, listeners in other network namespaces and systemd units; with a
+// host set, lsof runs there over ssh instead. Service naming, protection
+// rules and fingerprinting wrap whichever is chosen, though remote listeners
+// are not fingerprinted.
+func newProvider(cfg config.Config) (ports.Provider, error) {
+	var provider ports.Provider
+	switch {
+	case os.Getenv("PZAPP_USE_MOCK") == "1" || cfg.Provider == "mock":
+		provider = ports.NewMockProvider()
+	case cfg.Host != "":
+		provider = ports.RemoteProvider{Remote: cfg.SSH.Remote(cfg.Host)}
+	default:
+		provider = ports.SystemdProvider{Provider: ports.NamespaceProvider{Provider: ports.HiddenProvider{Provider: ports.NewSystemProvider()}}}
+	}
+
+	services, err := ports.NewServiceDB(cfg.Services)
+	if err != nil {
+		return nil, err
+	}
+	provider = ports.ServiceProvider{Provider: provider, Services: services}
+	if rules := cfg.Protection.RuleSet(); len(rules) > 0 {
+		provider = ports.ProtectProvider{Provider: provider, Rules: rules}
+	}
+	if cfg.Fingerprint.Enabled && cfg.Host == "" {
+		provider = ports.FingerprintProvider{
+			Provider:      provider,
+			Fingerprinter: ports.NewFingerprinter(cfg.Fingerprint.Timeout),
+		}
 	}
+	return provider, nil
 }
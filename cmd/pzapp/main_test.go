@@ -0,0 +1,36 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"portkiller/internal/config"
+)
+
+func TestSudoKillCommandForwardsStatePaths(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+	cfg := config.Default()
+	cfg.Audit.Enabled = true
+
+	helper, err := sudoKillCommand(cfg, "/home/dev/.config/pzapp/config.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"--config", "/home/dev/.config/pzapp/config.toml", "kill",
+		"--history-file", filepath.Join(state, "pzapp", "history.jsonl"),
+		"--audit-file", filepath.Join(state, "pzapp", "audit.log"),
+	}
+	if !reflect.DeepEqual(helper[1:], want) {
+		t.Fatalf("sudoKillCommand() = %q, want the executable then %q", helper, want)
+	}
+
+	// Nothing is forwarded for records that are not kept in files.
+	cfg.History.Enabled = false
+	cfg.Audit.Sink = "syslog"
+	if helper, _ = sudoKillCommand(cfg, "/etc/pzapp.toml"); len(helper) != 4 {
+		t.Fatalf("sudoKillCommand() without history or audit file = %q", helper)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// manEnvironment documents the environment variables pzapp reads.
+var manEnvironment = [][2]string{
+	{"NO_COLOR", "When set, use the monochrome theme and reduced motion."},
+	{"PZAPP_USE_MOCK", "When 1, list mock ports instead of the system's, for demos."},
+	{"PZAPP_TOKEN", "Bearer token clients of pzapp serve must send, unless --token-file is given."},
+	{"XDG_CONFIG_HOME", "Where the config file lives, default ~/.config."},
+	{"XDG_STATE_HOME", "Where preferences, kill history and the audit log live, default ~/.local/state."},
+}
+
+// manFiles documents the files pzapp uses.
+var manFiles = [][2]string{
+	{"$XDG_CONFIG_HOME/pzapp/config.toml", "Configuration; see pzapp config print for every key."},
+	{"$XDG_STATE_HOME/pzapp/prefs.json", "Quick toggles remembered between sessions."},
+	{"$XDG_STATE_HOME/pzapp/history.jsonl", "Kill history, when [history] is enabled."},
+	{"$XDG_STATE_HOME/pzapp/audit.log", "Audit log, when [audit] is enabled with the file sink."},
+}
+
+//go:generate sh -c "go run . man > ../../docs/pzapp.1"
+
+// runMan implements `pzapp man`, printing the man page generated from the
+// command tree: `pzapp man > pzapp.1`.
+func runMan(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("man: unexpected arguments")
+	}
+	writeMan(os.Stdout)
+	return nil
+}
+
+// writeMan writes the pzapp(1) man page in roff.
+func writeMan(w io.Writer) {
+	fmt.Fprintln(w, `.TH PZAPP 1 "" "pzapp" "User Commands"`)
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintln(w, `pzapp \- find and kill the processes listening on network ports`)
+
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintln(w, `.B pzapp`)
+	fmt.Fprintln(w, `[\fIflags\fR]`)
+	for _, cmd := range commandTree() {
+		fmt.Fprintln(w, ".br")
+		fmt.Fprintf(w, ".B pzapp %s\n", cmd.name)
+		if cmd.synopsis != "" {
+			fmt.Fprintln(w, roff(cmd.synopsis))
+		}
+	}
+
+	fmt.Fprintln(w, ".SH DESCRIPTION")
+	fmt.Fprintln(w, roff("Without a command, pzapp starts an interactive list of listening ports. Select a port to see its process and kill it; protection rules, the kill history and the audit log apply to every kill, whichever command makes it."))
+
+	fmt.Fprintln(w, ".SH OPTIONS")
+	writeManFlags(w, globalFlags(new(globalOptions)))
+
+	fmt.Fprintln(w, ".SH COMMANDS")
+	for _, cmd := range commandTree() {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, ".B %s\n", roff(strings.TrimSpace(cmd.name+" "+cmd.synopsis)))
+		fmt.Fprintln(w, roff(capitalize(cmd.summary)+"."))
+		if cmd.flags != nil {
+			fmt.Fprintln(w, ".RS")
+			writeManFlags(w, cmd.flags())
+			fmt.Fprintln(w, ".RE")
+		}
+	}
+
+	fmt.Fprintln(w, ".SH ENVIRONMENT")
+	writeManList(w, manEnvironment)
+	fmt.Fprintln(w, ".SH FILES")
+	writeManList(w, manFiles)
+	fmt.Fprintln(w, ".SH EXIT STATUS")
+	fmt.Fprintln(w, "0 on success, 1 on failure and 2 for invalid usage.")
+}
+
+func writeManFlags(w io.Writer, fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+		fmt.Fprintln(w, ".TP")
+		if name == "" {
+			fmt.Fprintf(w, `.B \-\-%s`+"\n", roff(f.Name))
+		} else {
+			fmt.Fprintf(w, `.BI \-\-%s " %s"`+"\n", roff(f.Name), roff(name))
+		}
+		if f.DefValue != "" && f.DefValue != "0" && f.DefValue != "false" {
+			usage += fmt.Sprintf(" (default %s)", f.DefValue)
+		}
+		fmt.Fprintln(w, roff(usage))
+	})
+}
+
+func writeManList(w io.Writer, items [][2]string) {
+	for _, item := range items {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, ".B %s\n", roff(item[0]))
+		fmt.Fprintln(w, roff(item[1]))
+	}
+}
+
+// roff escapes text for use as a roff text line.
+func roff(text string) string {
+	text = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
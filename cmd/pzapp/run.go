@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"portkiller/internal/audit"
+	"portkiller/internal/config"
+	"portkiller/internal/killer"
+	"portkiller/internal/ports"
+)
+
+// Conflict resolutions accepted by `pzapp run --on-conflict`.
+var conflictActions = []string{"ask", "kill", "next", "abort"}
+
+// freeTimeout bounds waiting for a killed holder to release the port.
+const freeTimeout = 10 * time.Second
+
+// runOptions are the flags of `pzapp run`.
+type runOptions struct {
+	port       int
+	onConflict string
+}
+
+func runFlags(opts *runOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("pzapp run", flag.ContinueOnError)
+	fs.IntVar(&opts.port, "port", 0, "port the command will listen on")
+	fs.StringVar(&opts.onConflict, "on-conflict", "ask", "when the port is held: "+strings.Join(conflictActions, ", "))
+	return fs
+}
+
+// runRun implements `pzapp run --port N -- command...`: it makes sure port
+// N is free, by killing its holder or moving to the next free port, then
+// replaces pzapp with the command, with PORT set to the port to use.
+func runRun(cfg config.Config, args []string) error {
+	var opts runOptions
+	fs := runFlags(&opts)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: pzapp run --port N [--on-conflict ask|kill|next|abort] -- command [args...]\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if opts.port <= 0 || opts.port > 65535 || fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("run: --port and a command are required")
+	}
+	if cfg.Host != "" {
+		return fmt.Errorf("run: --host is not supported; the command runs on this machine")
+	}
+	if !validConflictAction(opts.onConflict) {
+		return fmt.Errorf("run: unknown --on-conflict %q (want one of %s)", opts.onConflict, strings.Join(conflictActions, ", "))
+	}
+	argv := fs.Args()
+
+	use := opts.port
+	if holders, held := portHolders(cfg, opts.port); held {
+		describeHolders(os.Stderr, opts.port, holders)
+		action := opts.onConflict
+		if action == "ask" {
+			var err error
+			if action, err = askConflict(os.Stdin, os.Stderr); err != nil {
+				return err
+			}
+		}
+
+		switch action {
+		case "kill":
+			if err := killHolders(cfg, opts.port, holders); err != nil {
+				return err
+			}
+		case "next":
+			next, err := ports.NextFreePort(opts.port + 1)
+			if err != nil {
+				return fmt.Errorf("run: %w", err)
+			}
+			log.Printf("pzapp: using port %d instead", next)
+			use = next
+		default:
+			return fmt.Errorf("run: port %d is in use", opts.port)
+		}
+	}
+
+	return execCommand(argv, withPort(os.Environ(), use))
+}
+
+// withPort returns environ with PORT set to port. An inherited PORT is
+// dropped rather than shadowed: getenv returns the first of duplicate
+// entries, so the child would otherwise see the stale one.
+func withPort(environ []string, port int) []string {
+	env := make([]string, 0, len(environ)+1)
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, "PORT=") {
+			env = append(env, kv)
+		}
+	}
+	return append(env, "PORT="+strconv.Itoa(port))
+}
+
+func validConflictAction(action string) bool {
+	for _, known := range conflictActions {
+		if action == known {
+			return true
+		}
+	}
+	return false
+}
+
+// portHolders lists the TCP sockets on port and reports whether it is held.
+// A port can be held by a socket the provider does not see, in which case
+// the holders are empty.
+func portHolders(cfg config.Config, port int) ([]ports.Port, bool) {
+	var holders []ports.Port
+	cfg.Fingerprint.Enabled = false
+	if provider, err := newProvider(cfg); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ListTimeout)
+		defer cancel()
+		entries, _ := provider.List(ctx)
+		holders = tcpHolders(entries, port)
+	}
+	return holders, len(holders) > 0 || !ports.PortFree(port)
+}
+
+// tcpHolders picks the TCP sockets on port out of entries. Listeners in
+// other network namespaces are left out: a container's internal port does
+// not block the host's.
+func tcpHolders(entries []ports.Port, port int) []ports.Port {
+	var holders []ports.Port
+	for _, entry := range entries {
+		if entry.Port == port && strings.EqualFold(entry.Protocol, "tcp") && entry.Reachable() {
+			holders = append(holders, entry)
+		}
+	}
+	return holders
+}
+
+func describeHolders(w io.Writer, port int, holders []ports.Port) {
+	if len(holders) == 0 {
+		fmt.Fprintf(w, "Port %d is in use by a process pzapp cannot see (try sudo).\n", port)
+		return
+	}
+	fmt.Fprintf(w, "Port %d is held by:\n", port)
+	for _, h := range holders {
+		if h.Hidden {
+			fmt.Fprintf(w, "  %s (user %s) on %s\n", h.Process, h.User, h.Address)
+			continue
+		}
+		fmt.Fprintf(w, "  %s (PID %d, user %s) on %s", h.Process, h.PID, h.User, h.Address)
+		if h.Cmdline != "" {
+			fmt.Fprintf(w, ": %s", h.Cmdline)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// askConflict asks how to resolve the conflict. Without a terminal to ask
+// on, it aborts.
+func askConflict(in *os.File, out io.Writer) (string, error) {
+	if info, err := in.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return "abort", nil
+	}
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprintf(out, "[k]ill the holder, use the [n]ext free port, or [a]bort? ")
+		line, err := reader.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "k", "kill":
+			return "kill", nil
+		case "n", "next":
+			return "next", nil
+		case "a", "abort", "q":
+			return "abort", nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return "abort", nil
+			}
+			return "", fmt.Errorf("run: %w", err)
+		}
+	}
+}
+
+// killHolders terminates every process holding port, with the protection
+// rules, history and audit log of `pzapp kill`, then waits for the port to
+// free.
+func killHolders(cfg config.Config, port int, holders []ports.Port) error {
+	if len(holders) == 0 {
+		return fmt.Errorf("run: cannot kill the holder of port %d: it is not visible to pzapp", port)
+	}
+	auditor, err := openAudit(cfg, audit.SourceCLI)
+	if err != nil {
+		return err
+	}
+	defer auditor.Close()
+	k, err := killer.New(cfg, auditor)
+	if err != nil {
+		return err
+	}
+
+	killed := make(map[int]bool)
+	for _, holder := range holders {
+		if killed[holder.PID] {
+			continue
+		}
+		result := k.Kill(holder, false)
+		if result.HistoryErr != nil {
+			log.Printf("pzapp: recording kill history: %v", result.HistoryErr)
+		}
+		if result.AuditErr != nil {
+			log.Printf("pzapp: writing audit log: %v", result.AuditErr)
+		}
+		if result.Err != nil {
+			return fmt.Errorf("run: %w", result.Err)
+		}
+		killed[holder.PID] = true
+		fmt.Fprintf(os.Stderr, "Terminated %s (PID %d)\n", holder.Process, holder.PID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), freeTimeout)
+	defer cancel()
+	for _, holder := range holders {
+		if err := ports.WaitPort(ctx, holder, false); err != nil {
+			return fmt.Errorf("run: %w", err)
+		}
+	}
+	return nil
+}
+
+// lookCommand resolves argv[0] on PATH for execCommand.
+func lookCommand(argv []string) (string, error) {
+	path, err := exec.LookPath(argv[0])
+	if err != nil {
+		return "", fmt.Errorf("run: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"portkiller/internal/ports"
+)
+
+func TestTCPHoldersSkipsOtherNamespaces(t *testing.T) {
+	entries := []ports.Port{
+		{PID: 10, Process: "node", Protocol: "TCP", Port: 3000, Address: "*"},
+		{PID: 10, Process: "node", Protocol: "UDP", Port: 3000, Address: "*"},
+		{PID: 20, Process: "vite", Protocol: "TCP", Port: 3001, Address: "*"},
+		{PID: 30, Process: "node", Protocol: "tcp", Port: 3000, Address: "*", Container: "docker:3f2a1b9c4d5e", NetNS: "net:[4026532451]"},
+	}
+	holders := tcpHolders(entries, 3000)
+	if len(holders) != 1 || holders[0].PID != 10 {
+		t.Fatalf("tcpHolders(3000) = %+v, want only the host's node", holders)
+	}
+}
+
+func TestExecdCommandSeesChosenPort(t *testing.T) {
+	switch {
+	case os.Getenv("PZAPP_TEST_PRINT_PORT") == "1":
+		// The command: report PORT as Go, like glibc, reads it.
+		fmt.Print(os.Getenv("PORT"))
+		os.Exit(0)
+	case os.Getenv("PZAPP_TEST_EXEC") == "1":
+		// pzapp: exec the command the way `pzapp run` does, with PORT=8080
+		// inherited from the test.
+		env := append(os.Environ(), "PZAPP_TEST_PRINT_PORT=1")
+		err := execCommand([]string{os.Args[0], "-test.run=^TestExecdCommandSeesChosenPort$"}, withPort(env, 3001))
+		t.Fatalf("exec: %v", err)
+	}
+
+	// exec.Cmd drops duplicate variables itself, so the test binary is
+	// re-run to exec the command without it.
+	cmd := exec.Command(os.Args[0], "-test.run=^TestExecdCommandSeesChosenPort$")
+	cmd.Env = append(os.Environ(), "PZAPP_TEST_EXEC=1", "PORT=8080")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("helper: %v (%s)", err, out)
+	}
+	if string(out) != "3001" {
+		t.Fatalf("command saw PORT=%q, want 3001", out)
+	}
+}
+
+func TestWithPort(t *testing.T) {
+	env := withPort([]string{"HOME=/home/dev", "PORT=8080", "PORTAL=x", "PORT=9090"}, 3001)
+	want := []string{"HOME=/home/dev", "PORTAL=x", "PORT=3001"}
+	if !reflect.DeepEqual(env, want) {
+		t.Fatalf("withPort = %q, want %q", env, want)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"portkiller/internal/api"
+	"portkiller/internal/audit"
+	"portkiller/internal/config"
+	"portkiller/internal/killer"
+)
+
+// shutdownTimeout bounds waiting for in-flight requests on exit.
+const shutdownTimeout = 5 * time.Second
+
+// serveOptions are the flags of `pzapp serve`.
+type serveOptions struct {
+	listen    string
+	tokenFile string
+	interval  time.Duration
+	readOnly  bool
+}
+
+func serveFlags(opts *serveOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("pzapp serve", flag.ContinueOnError)
+	fs.StringVar(&opts.listen, "listen", "127.0.0.1:7777", "host:port, or unix:/path for a unix socket")
+	fs.StringVar(&opts.tokenFile, "token-file", "", "file holding the bearer token clients must send (default $PZAPP_TOKEN)")
+	fs.DurationVar(&opts.interval, "interval", api.DefaultInterval, "how often the event stream re-lists ports")
+	fs.BoolVar(&opts.readOnly, "read-only", false, "serve the port list, events and metrics but refuse kills")
+	return fs
+}
+
+// runServe implements `pzapp serve`: the port list, kills and Prometheus
+// metrics over HTTP, on a TCP address or a unix socket.
+func runServe(cfg config.Config, args []string) error {
+	var opts serveOptions
+	fs := serveFlags(&opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || opts.interval <= 0 {
+		fs.Usage()
+		return fmt.Errorf("serve: unexpected arguments")
+	}
+
+	token, generated, err := serveToken(opts)
+	if err != nil {
+		return fmt.Errorf("serve: %w", err)
+	}
+
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return err
+	}
+	auditor, err := openAudit(cfg, audit.SourceAPI)
+	if err != nil {
+		return err
+	}
+	defer auditor.Close()
+	k, err := killer.New(cfg, auditor)
+	if err != nil {
+		return err
+	}
+
+	ln, err := listenOn(opts.listen)
+	if err != nil {
+		return fmt.Errorf("serve: %w", err)
+	}
+	if generated {
+		log.Printf("pzapp: no token set; clients must send Authorization: Bearer %s", token)
+	}
+
+	srv := &api.Server{
+		Provider: provider,
+		Killer:   k,
+		Token:    token,
+		Hosts:    allowedHosts(ln.Addr()),
+		Timeout:  cfg.ListTimeout,
+		Interval: opts.interval,
+		ReadOnly: opts.readOnly,
+	}
+	httpServer := &http.Server{Handler: srv.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		httpServer.Shutdown(shutdown)
+	}()
+
+	log.Printf("pzapp: serving on %s", ln.Addr())
+	if err := httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+	return nil
+}
+
+// serveToken returns the bearer token from --token-file or $PZAPP_TOKEN.
+// Over TCP anything on the machine, web pages included, can reach the
+// server, so without either a random token is generated; only unix sockets
+// may go without one.
+func serveToken(opts serveOptions) (token string, generated bool, err error) {
+	token = os.Getenv("PZAPP_TOKEN")
+	if opts.tokenFile != "" {
+		data, err := os.ReadFile(opts.tokenFile)
+		if err != nil {
+			return "", false, err
+		}
+		token = strings.TrimSpace(string(data))
+		if token == "" {
+			return "", false, fmt.Errorf("%s is empty", opts.tokenFile)
+		}
+	}
+	if token != "" || strings.HasPrefix(opts.listen, "unix:") {
+		return token, false, nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, err
+	}
+	return hex.EncodeToString(b), true, nil
+}
+
+// allowedHosts returns the Host headers a server on addr answers: the
+// address itself, and localhost for loopback. Servers on every interface
+// and unix sockets accept any Host and rely on the token instead.
+func allowedHosts(addr net.Addr) []string {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok || tcp.IP.IsUnspecified() {
+		return nil
+	}
+	port := strconv.Itoa(tcp.Port)
+	hosts := []string{net.JoinHostPort(tcp.IP.String(), port)}
+	if tcp.IP.IsLoopback() {
+		for _, alias := range []string{"localhost", "127.0.0.1", "::1"} {
+			if host := net.JoinHostPort(alias, port); host != hosts[0] {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	return hosts
+}
+
+// listenOn opens a TCP listener, or a unix socket for `unix:/path`. A stale
+// socket file is replaced, and a new one is only accessible to its owner.
+func listenOn(address string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(address, "unix:")
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
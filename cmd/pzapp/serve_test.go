@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestServeToken(t *testing.T) {
+	t.Setenv("PZAPP_TOKEN", "")
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if token, generated, err := serveToken(serveOptions{listen: "127.0.0.1:7777", tokenFile: file}); token != "s3cret" || generated || err != nil {
+		t.Errorf("token from file = %q, %v, %v", token, generated, err)
+	}
+	if token, generated, err := serveToken(serveOptions{listen: "unix:/tmp/pzapp.sock"}); token != "" || generated || err != nil {
+		t.Errorf("unix socket token = %q, %v, %v; want none", token, generated, err)
+	}
+	first, generated, err := serveToken(serveOptions{listen: "127.0.0.1:7777"})
+	if len(first) != 32 || !generated || err != nil {
+		t.Fatalf("TCP without a token = %q, %v, %v; want a generated one", first, generated, err)
+	}
+	if second, _, _ := serveToken(serveOptions{listen: "127.0.0.1:7777"}); second == first {
+		t.Errorf("generated the same token twice: %q", first)
+	}
+
+	t.Setenv("PZAPP_TOKEN", "from-env")
+	if token, generated, _ := serveToken(serveOptions{listen: ":9777"}); token != "from-env" || generated {
+		t.Errorf("token from $PZAPP_TOKEN = %q, %v", token, generated)
+	}
+}
+
+func TestAllowedHosts(t *testing.T) {
+	cases := []struct {
+		addr net.Addr
+		want []string
+	}{
+		{&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7777}, []string{"127.0.0.1:7777", "localhost:7777", "[::1]:7777"}},
+		{&net.TCPAddr{IP: net.ParseIP("192.168.1.5"), Port: 80}, []string{"192.168.1.5:80"}},
+		{&net.TCPAddr{IP: net.IPv6unspecified, Port: 9777}, nil},
+		{&net.UnixAddr{Name: "/tmp/pzapp.sock", Net: "unix"}, nil},
+	}
+	for _, c := range cases {
+		if got := allowedHosts(c.addr); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("allowedHosts(%v) = %q, want %q", c.addr, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+// Package api serves the port list and kills over HTTP, for editor plugins
+// and scripts that would rather not scrape the TUI.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"portkiller/internal/killer"
+	"portkiller/internal/ports"
+	"portkiller/internal/query"
+)
+
+// DefaultInterval is how often the event stream re-lists ports when no
+// interval is configured.
+const DefaultInterval = 2 * time.Second
+
+// Server answers API requests from a provider, killing through a Killer so
+// protection rules, history and the audit log apply as they do in the TUI.
+type Server struct {
+	Provider ports.Provider
+	Killer   *killer.Killer
+	// Token, when set, must be presented as `Authorization: Bearer <token>`.
+	Token string
+	// Hosts, when set, are the only Host headers accepted, so a page that
+	// rebinds its own DNS name to the listen address cannot read the API.
+	Hosts []string
+	// Timeout bounds each provider listing.
+	Timeout time.Duration
+	// Interval is how often the event stream re-lists ports.
+	Interval time.Duration
+	// ReadOnly refuses kills, for exposing the list and metrics only.
+	ReadOnly bool
+
+	// killMu serialises kills, so two clients racing for the same port do
+	// not signal its process twice.
+	killMu sync.Mutex
+	// listErrors counts failed provider listings for /metrics.
+	listErrors atomic.Uint64
+}
+
+// Entry is the JSON form of a ports.Port.
+type Entry struct {
+	PID         int        `json:"pid"`
+	Process     string     `json:"process"`
+	User        string     `json:"user"`
+	Protocol    string     `json:"protocol"`
+	Port        int        `json:"port"`
+	Address     string     `json:"address"`
+	State       string     `json:"state,omitempty"`
+	Cmdline     string     `json:"cmdline,omitempty"`
+	StartTime   *time.Time `json:"start_time,omitempty"`
+	Service     string     `json:"service,omitempty"`
+	Unit        string     `json:"unit,omitempty"`
+	UserUnit    bool       `json:"user_unit,omitempty"`
+	Container   string     `json:"container,omitempty"`
+	NetNS       string     `json:"netns,omitempty"`
+	AppProtocol string     `json:"app_protocol,omitempty"`
+	Protection  string     `json:"protection,omitempty"`
+	ProtectedBy string     `json:"protected_by,omitempty"`
+	Hidden      bool       `json:"hidden,omitempty"`
+}
+
+// NewEntry converts p to its JSON form.
+func NewEntry(p ports.Port) Entry {
+	e := Entry{
+		PID:         p.PID,
+		Process:     p.Process,
+		User:        p.User,
+		Protocol:    strings.ToLower(p.Protocol),
+		Port:        p.Port,
+		Address:     p.Address,
+		State:       p.State,
+		Cmdline:     p.Cmdline,
+		Service:     p.Service,
+		Unit:        p.Unit,
+		UserUnit:    p.UserUnit,
+		Container:   p.Container,
+		NetNS:       p.NetNS,
+		AppProtocol: p.AppProtocol,
+		Protection:  p.Protection,
+		ProtectedBy: p.ProtectedBy,
+		Hidden:      p.Hidden,
+	}
+	if !p.StartTime.IsZero() {
+		start := p.StartTime
+		e.StartTime = &start
+	}
+	return e
+}
+
+// KillRequest is the optional body of POST /ports/{port}/kill. PID and
+// Protocol narrow which of the port's processes are killed; Confirm is
+// required for processes protected with ports.ProtectConfirm.
+type KillRequest struct {
+	PID      int    `json:"pid,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+	Signal   string `json:"signal,omitempty"`
+	Confirm  bool   `json:"confirm,omitempty"`
+}
+
+// KillResult is the outcome of killing one process.
+type KillResult struct {
+	PID        int    `json:"pid"`
+	Process    string `json:"process"`
+	Signal     string `json:"signal"`
+	Terminated bool   `json:"terminated"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Handler returns the API's routes, refusing browser requests and, when a
+// token is set, requests without it.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ports", s.handleList)
+	mux.HandleFunc("GET /ports/{port}", s.handlePort)
+	mux.HandleFunc("POST /ports/{port}/kill", s.handleKill)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	var handler http.Handler = mux
+	if s.Token != "" {
+		handler = s.authenticate(handler)
+	}
+	return s.rejectBrowsers(handler)
+}
+
+// rejectBrowsers keeps web pages away from the API. Browsers send Origin on
+// cross-origin requests, a rebound DNS name shows up in Host, and a POST
+// with a JSON content type cannot be sent cross-origin without a preflight
+// the API never answers; none of this concerns editor plugins or scripts.
+func (s *Server) rejectBrowsers(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" {
+			writeError(w, http.StatusForbidden, errors.New("cross-origin requests are not allowed"))
+			return
+		}
+		if len(s.Hosts) > 0 && !containsHost(s.Hosts, r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("unexpected Host %q", r.Host))
+			return
+		}
+		if r.Method == http.MethodPost {
+			if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, errors.New("POST requests must be sent as application/json"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func containsHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate rejects requests without the bearer token.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	want := []byte("Bearer " + s.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="pzapp"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleList serves GET /ports, optionally narrowed by a `q` filter in the
+// TUI's query language.
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	q, err := query.Parse(r.URL.Query().Get("q"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	entries, err := s.list(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toEntries(q.Filter(entries)))
+}
+
+// handlePort serves GET /ports/{port}: every socket on the port, of either
+// protocol and any address.
+func (s *Server) handlePort(w http.ResponseWriter, r *http.Request) {
+	port, err := pathPort(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	entries, err := s.list(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	matches := onPort(entries, port, "", 0)
+	if len(matches) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("nothing is listening on port %d", port))
+		return
+	}
+	writeJSON(w, http.StatusOK, toEntries(matches))
+}
+
+// handleKill serves POST /ports/{port}/kill, terminating each process
+// holding the port once. The response lists every outcome; its status is
+// that of the first failure.
+func (s *Server) handleKill(w http.ResponseWriter, r *http.Request) {
+	if s.ReadOnly {
+		writeError(w, http.StatusForbidden, errors.New("kills are disabled on this server"))
+		return
+	}
+	port, err := pathPort(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var req KillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	k := *s.Killer
+	if req.Signal != "" {
+		name, err := ports.ParseSignal(req.Signal)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		k.Policy.Signal = name
+	}
+
+	entries, err := s.list(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	targets := onPort(entries, port, req.Protocol, req.PID)
+	if req.PID == 0 {
+		// A container's listener only shares the port number; it takes
+		// an explicit PID to kill it.
+		targets = reachable(targets)
+	}
+	if len(targets) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("nothing matching is listening on port %d", port))
+		return
+	}
+
+	s.killMu.Lock()
+	defer s.killMu.Unlock()
+	status := http.StatusOK
+	results := []KillResult{}
+	killed := make(map[int]bool)
+	for _, target := range targets {
+		if killed[target.PID] && !target.Hidden {
+			continue
+		}
+		killed[target.PID] = true
+		result := k.Kill(target, req.Confirm)
+		outcome := KillResult{
+			PID:        target.PID,
+			Process:    target.Process,
+			Signal:     strings.ToUpper(k.Policy.Signal),
+			Terminated: result.Err == nil,
+		}
+		if result.Err != nil {
+			outcome.Error = result.Err.Error()
+			if status == http.StatusOK {
+				status = killStatus(target, result.Err)
+			}
+		}
+		results = append(results, outcome)
+	}
+	writeJSON(w, status, map[string]any{"results": results})
+}
+
+// killStatus maps a failed kill to an HTTP status.
+func killStatus(target ports.Port, err error) int {
+	var protected *killer.ProtectedError
+	switch {
+	case errors.As(err, &protected):
+		if protected.Target.Protection == ports.ProtectConfirm {
+			return http.StatusConflict
+		}
+		return http.StatusForbidden
+	case target.Hidden, errors.Is(err, os.ErrPermission):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+func (s *Server) list(ctx context.Context) ([]ports.Port, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	entries, err := s.Provider.List(ctx)
+	if err != nil {
+		s.listErrors.Add(1)
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Port != entries[j].Port {
+			return entries[i].Port < entries[j].Port
+		}
+		return entries[i].PID < entries[j].PID
+	})
+	return entries, nil
+}
+
+// onPort returns the entries on port, narrowed to protocol and pid when
+// they are set.
+func onPort(entries []ports.Port, port int, protocol string, pid int) []ports.Port {
+	var matches []ports.Port
+	for _, entry := range entries {
+		if entry.Port != port {
+			continue
+		}
+		if protocol != "" && !strings.EqualFold(entry.Protocol, protocol) {
+			continue
+		}
+		if pid != 0 && entry.PID != pid {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+	return matches
+}
+
+// reachable drops the entries in other network namespaces.
+func reachable(entries []ports.Port) []ports.Port {
+	var kept []ports.Port
+	for _, entry := range entries {
+		if entry.Reachable() {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+func pathPort(r *http.Request) (int, error) {
+	port, err := strconv.Atoi(r.PathValue("port"))
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", r.PathValue("port"))
+	}
+	return port, nil
+}
+
+func toEntries(entries []ports.Port) []Entry {
+	out := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, NewEntry(entry))
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
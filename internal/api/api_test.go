@@ -0,0 +1,365 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"portkiller/internal/killer"
+	"portkiller/internal/ports"
+)
+
+// fakeProvider serves a fixed listing that tests may swap.
+type fakeProvider struct {
+	mu      sync.Mutex
+	entries []ports.Port
+}
+
+func (p *fakeProvider) List(context.Context) ([]ports.Port, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ports.Port(nil), p.entries...), nil
+}
+
+func (p *fakeProvider) set(entries []ports.Port) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = entries
+}
+
+func newTestServer(t *testing.T, token string, entries ...ports.Port) (*httptest.Server, *fakeProvider) {
+	t.Helper()
+	provider := &fakeProvider{entries: entries}
+	srv := &Server{
+		Provider: provider,
+		Killer:   &killer.Killer{Policy: ports.KillPolicy{Signal: "TERM", Grace: 100 * time.Millisecond, Escalate: true, KillWait: time.Second}},
+		Token:    token,
+		Interval: 50 * time.Millisecond,
+	}
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts, provider
+}
+
+func do(t *testing.T, method, url, token, body string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if method == "POST" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return send(t, req)
+}
+
+func send(t *testing.T, req *http.Request) (*http.Response, []byte) {
+	t.Helper()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, data
+}
+
+func TestListAndPort(t *testing.T) {
+	ts, _ := newTestServer(t, "",
+		ports.Port{PID: 20, Process: "node", Protocol: "TCP", Port: 3000, Address: "127.0.0.1"},
+		ports.Port{PID: 10, Process: "postgres", Protocol: "TCP", Port: 5432, Address: "*"},
+		ports.Port{PID: 10, Process: "postgres", Protocol: "TCP", Port: 5432, Address: "::"},
+	)
+
+	resp, body := do(t, "GET", ts.URL+"/ports", "", "")
+	var all []Entry
+	if err := json.Unmarshal(body, &all); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /ports = %d %s (%v)", resp.StatusCode, body, err)
+	}
+	if len(all) != 3 || all[0].Port != 3000 || all[0].Protocol != "tcp" {
+		t.Fatalf("GET /ports = %+v, want three entries sorted by port", all)
+	}
+
+	_, body = do(t, "GET", ts.URL+"/ports?q=proc:node", "", "")
+	var filtered []Entry
+	json.Unmarshal(body, &filtered)
+	if len(filtered) != 1 || filtered[0].Process != "node" {
+		t.Fatalf("GET /ports?q=proc:node = %s", body)
+	}
+	if resp, _ := do(t, "GET", ts.URL+"/ports?q=bogus:x", "", ""); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("invalid query answered %d, want 400", resp.StatusCode)
+	}
+
+	_, body = do(t, "GET", ts.URL+"/ports/5432", "", "")
+	var onPort []Entry
+	json.Unmarshal(body, &onPort)
+	if len(onPort) != 2 {
+		t.Fatalf("GET /ports/5432 = %s, want both sockets", body)
+	}
+	if resp, _ := do(t, "GET", ts.URL+"/ports/9999", "", ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET /ports/9999 = %d, want 404", resp.StatusCode)
+	}
+	if resp, _ := do(t, "GET", ts.URL+"/ports/http", "", ""); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("GET /ports/http = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	ts, _ := newTestServer(t, "s3cret", ports.Port{PID: 1, Protocol: "TCP", Port: 22})
+
+	resp, _ := do(t, "GET", ts.URL+"/ports", "", "")
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+		t.Fatalf("request without a token = %d, want 401 with a challenge", resp.StatusCode)
+	}
+	if resp, _ := do(t, "GET", ts.URL+"/ports", "wrong", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("request with a wrong token = %d, want 401", resp.StatusCode)
+	}
+	if resp, _ := do(t, "GET", ts.URL+"/ports", "s3cret", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("request with the token = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestRejectsBrowserRequests(t *testing.T) {
+	srv := &Server{
+		Provider: &fakeProvider{entries: []ports.Port{{PID: 999999, Protocol: "TCP", Port: 5432}}},
+		ReadOnly: true,
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	srv.Hosts = []string{strings.TrimPrefix(ts.URL, "http://"), "localhost:7777"}
+
+	cases := []struct {
+		name        string
+		method      string
+		host        string
+		origin      string
+		contentType string
+		want        int
+	}{
+		{name: "plain GET", method: "GET", want: http.StatusOK},
+		{name: "allowed alias", method: "GET", host: "LOCALHOST:7777", want: http.StatusOK},
+		{name: "cross-origin GET", method: "GET", origin: "https://evil.example", want: http.StatusForbidden},
+		{name: "null origin", method: "GET", origin: "null", want: http.StatusForbidden},
+		{name: "rebound host", method: "GET", host: "evil.example:7777", want: http.StatusForbidden},
+		{name: "form POST", method: "POST", contentType: "text/plain", want: http.StatusUnsupportedMediaType},
+		{name: "POST without type", method: "POST", want: http.StatusUnsupportedMediaType},
+		{name: "no-cors POST", method: "POST", origin: "https://evil.example", contentType: "text/plain", want: http.StatusForbidden},
+		{name: "JSON POST", method: "POST", contentType: "application/json; charset=utf-8", want: http.StatusForbidden},
+	}
+	for _, c := range cases {
+		path := "/ports"
+		if c.method == "POST" {
+			path = "/ports/5432/kill"
+		}
+		req, err := http.NewRequest(c.method, ts.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.host != "" {
+			req.Host = c.host
+		}
+		if c.origin != "" {
+			req.Header.Set("Origin", c.origin)
+		}
+		if c.contentType != "" {
+			req.Header.Set("Content-Type", c.contentType)
+		}
+		// The read-only server answers an acceptable kill with 403 too, but
+		// with its own error.
+		resp, body := send(t, req)
+		if resp.StatusCode != c.want {
+			t.Errorf("%s = %d %s, want %d", c.name, resp.StatusCode, body, c.want)
+		}
+		if c.name == "JSON POST" && !strings.Contains(string(body), "disabled") {
+			t.Errorf("%s was refused before reaching the handler: %s", c.name, body)
+		}
+	}
+}
+
+func TestKillRespectsProtection(t *testing.T) {
+	ts, _ := newTestServer(t, "",
+		ports.Port{PID: 999999, Process: "sshd", Protocol: "TCP", Port: 22, Protection: ports.ProtectRefuse, ProtectedBy: "sshd"},
+		ports.Port{PID: 999998, Process: "postgres", Protocol: "TCP", Port: 5432, Protection: ports.ProtectConfirm, ProtectedBy: "postgres"},
+		ports.Port{Process: ports.HiddenOwner, Protocol: "TCP", Port: 631, Hidden: true},
+	)
+
+	cases := []struct {
+		port int
+		want int
+	}{
+		{22, http.StatusForbidden},
+		{5432, http.StatusConflict},
+		{631, http.StatusForbidden},
+		{8080, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		resp, body := do(t, "POST", ts.URL+"/ports/"+strconv.Itoa(c.port)+"/kill", "", "")
+		if resp.StatusCode != c.want {
+			t.Errorf("kill :%d = %d %s, want %d", c.port, resp.StatusCode, body, c.want)
+		}
+	}
+	if resp, _ := do(t, "POST", ts.URL+"/ports/5432/kill", "", `{"signal":"BOGUS"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("kill with an unknown signal = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestKillByPortSkipsOtherNamespaces(t *testing.T) {
+	// Refused, so a kill that reaches the container's process stops at the
+	// protection check instead of signalling anything.
+	ts, _ := newTestServer(t, "",
+		ports.Port{PID: 999997, Process: "nginx", Protocol: "TCP", Port: 8080, Address: "*",
+			Container: "docker:3f2a1b9c4d5e", NetNS: "net:[4026532451]", Protection: ports.ProtectRefuse, ProtectedBy: "test"},
+	)
+
+	if resp, body := do(t, "POST", ts.URL+"/ports/8080/kill", "", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("kill :8080 = %d %s, want 404 for a container's listener", resp.StatusCode, body)
+	}
+	if resp, body := do(t, "POST", ts.URL+"/ports/8080/kill", "", `{"pid":999997}`); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("kill :8080 with its PID = %d %s, want it to reach the protection check", resp.StatusCode, body)
+	}
+}
+
+func TestKillTerminatesProcess(t *testing.T) {
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	pid := cmd.Process.Pid
+	ts, _ := newTestServer(t, "",
+		ports.Port{PID: pid, Process: "sleep", Protocol: "TCP", Port: 4000, Address: "*"},
+		ports.Port{PID: pid, Process: "sleep", Protocol: "TCP", Port: 4000, Address: "::"},
+	)
+
+	resp, body := do(t, "POST", ts.URL+"/ports/4000/kill", "", `{"signal":"int"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("kill = %d %s", resp.StatusCode, body)
+	}
+	var out struct{ Results []KillResult }
+	json.Unmarshal(body, &out)
+	if len(out.Results) != 1 || !out.Results[0].Terminated || out.Results[0].Signal != "INT" {
+		t.Fatalf("kill results = %+v, want one INT kill", out.Results)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("process survived the kill")
+	}
+}
+
+func TestEventsStreamChanges(t *testing.T) {
+	ts, provider := newTestServer(t, "", ports.Port{PID: 1, Process: "a", Protocol: "TCP", Port: 1000})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	events := bufio.NewScanner(resp.Body)
+	next := func() (string, string) {
+		var event, data string
+		for events.Scan() {
+			line := events.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			case line == "" && event != "":
+				return event, data
+			}
+		}
+		t.Fatalf("stream ended: %v", events.Err())
+		return "", ""
+	}
+
+	if event, data := next(); event != "snapshot" || !strings.Contains(data, `"port":1000`) {
+		t.Fatalf("first event = %s %s, want a snapshot", event, data)
+	}
+	provider.set([]ports.Port{{PID: 2, Process: "b", Protocol: "TCP", Port: 2000}})
+	got := map[string]string{}
+	for range 2 {
+		event, data := next()
+		got[event] = data
+	}
+	if !strings.Contains(got["added"], `"port":2000`) || !strings.Contains(got["removed"], `"port":1000`) {
+		t.Fatalf("change events = %v, want :2000 added and :1000 removed", got)
+	}
+}
+
+// failingProvider always fails to list.
+type failingProvider struct{}
+
+func (failingProvider) List(context.Context) ([]ports.Port, error) {
+	return nil, errors.New("lsof: not found")
+}
+
+func TestMetrics(t *testing.T) {
+	ts, _ := newTestServer(t, "",
+		ports.Port{PID: 10, Process: "node", User: "dev", Protocol: "TCP", Port: 3000, Address: "*"},
+		ports.Port{PID: 10, Process: "node", User: "dev", Protocol: "TCP", Port: 3000, Address: "::"},
+		ports.Port{PID: 20, Process: `we"ird`, User: "dev", Protocol: "UDP", Port: 53},
+	)
+	resp, body := do(t, "GET", ts.URL+"/metrics", "", "")
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("GET /metrics = %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	for _, want := range []string{
+		"# TYPE pzapp_listening_ports gauge\n",
+		`pzapp_listening_ports{port="53",proto="udp",process="we\"ird",user="dev"} 1` + "\n",
+		`pzapp_listening_ports{port="3000",proto="tcp",process="node",user="dev"} 2` + "\n",
+		"pzapp_provider_up 1\n",
+		"pzapp_provider_errors_total 0\n",
+		"pzapp_provider_scrape_duration_seconds ",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+
+	srv := &Server{Provider: failingProvider{}}
+	failing := httptest.NewServer(srv.Handler())
+	defer failing.Close()
+	do(t, "GET", failing.URL+"/ports", "", "")
+	_, body = do(t, "GET", failing.URL+"/metrics", "", "")
+	if !strings.Contains(string(body), "pzapp_provider_up 0\n") || !strings.Contains(string(body), "pzapp_provider_errors_total 2\n") {
+		t.Errorf("metrics of a failing provider:\n%s", body)
+	}
+	if strings.Contains(string(body), "pzapp_listening_ports{") {
+		t.Errorf("a failing provider exported port series:\n%s", body)
+	}
+}
+
+func TestReadOnlyRefusesKills(t *testing.T) {
+	srv := &Server{Provider: &fakeProvider{entries: []ports.Port{{PID: 999999, Protocol: "TCP", Port: 3000}}}, ReadOnly: true}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	if resp, _ := do(t, "POST", ts.URL+"/ports/3000/kill", "", ""); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("kill on a read-only server = %d, want 403", resp.StatusCode)
+	}
+}
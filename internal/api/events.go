@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"portkiller/internal/ports"
+)
+
+// heartbeat is how often an idle event stream sends a comment, so proxies
+// do not close it.
+const heartbeat = 15 * time.Second
+
+// handleEvents serves GET /events as a Server-Sent Events stream. It opens
+// with a "snapshot" event holding every port, then re-lists every Interval
+// and sends an "added" or "removed" event for each socket that came or went.
+// A failed listing sends an "error" event and the stream carries on.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastWrite := time.Now()
+
+	var known map[string]ports.Port
+	for {
+		entries, err := s.list(r.Context())
+		switch {
+		case r.Context().Err() != nil:
+			return
+		case err != nil:
+			writeEvent(w, "error", map[string]string{"error": err.Error()})
+			lastWrite = time.Now()
+		case known == nil:
+			known = keyed(entries)
+			writeEvent(w, "snapshot", toEntries(entries))
+			lastWrite = time.Now()
+		default:
+			current := keyed(entries)
+			for _, entry := range entries {
+				if _, ok := known[entryKey(entry)]; !ok {
+					writeEvent(w, "added", NewEntry(entry))
+					lastWrite = time.Now()
+				}
+			}
+			for key, entry := range known {
+				if _, ok := current[key]; !ok {
+					writeEvent(w, "removed", NewEntry(entry))
+					lastWrite = time.Now()
+				}
+			}
+			known = current
+		}
+		if time.Since(lastWrite) >= heartbeat {
+			fmt.Fprint(w, ": keep-alive\n\n")
+			lastWrite = time.Now()
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event string, v any) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+// entryKey identifies a socket across listings; a restarted process comes
+// back as a removal and an addition.
+func entryKey(p ports.Port) string {
+	return strings.Join([]string{strings.ToLower(p.Protocol), p.Address, strconv.Itoa(p.Port), strconv.Itoa(p.PID)}, "|")
+}
+
+func keyed(entries []ports.Port) map[string]ports.Port {
+	m := make(map[string]ports.Port, len(entries))
+	for _, entry := range entries {
+		m[entryKey(entry)] = entry
+	}
+	return m
+}
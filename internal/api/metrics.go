@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"portkiller/internal/ports"
+)
+
+// listenerLabels identifies one pzapp_listening_ports series.
+type listenerLabels struct {
+	port    int
+	proto   string
+	process string
+	user    string
+}
+
+// handleMetrics serves GET /metrics in the Prometheus text exposition
+// format. Each scrape lists the ports afresh; a failed listing still
+// answers, with pzapp_provider_up at 0 and no port series, so alerts can
+// tell a broken provider from an empty host.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	entries, err := s.list(r.Context())
+	elapsed := time.Since(start)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetric(w, "pzapp_provider_scrape_duration_seconds", "gauge", "How long listing ports took during this scrape.")
+	fmt.Fprintf(w, "pzapp_provider_scrape_duration_seconds %s\n", strconv.FormatFloat(elapsed.Seconds(), 'g', -1, 64))
+	writeMetric(w, "pzapp_provider_errors_total", "counter", "Port listings that failed since pzapp serve started.")
+	fmt.Fprintf(w, "pzapp_provider_errors_total %d\n", s.listErrors.Load())
+	writeMetric(w, "pzapp_provider_up", "gauge", "Whether listing ports succeeded during this scrape.")
+	if err != nil {
+		fmt.Fprintln(w, "pzapp_provider_up 0")
+		return
+	}
+	fmt.Fprintln(w, "pzapp_provider_up 1")
+
+	counts := listenerCounts(entries)
+	series := make([]listenerLabels, 0, len(counts))
+	for labels := range counts {
+		series = append(series, labels)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		a, b := series[i], series[j]
+		if a.port != b.port {
+			return a.port < b.port
+		}
+		if a.proto != b.proto {
+			return a.proto < b.proto
+		}
+		if a.process != b.process {
+			return a.process < b.process
+		}
+		return a.user < b.user
+	})
+	writeMetric(w, "pzapp_listening_ports", "gauge", "Sockets listening on a port, by protocol, process and user.")
+	for _, l := range series {
+		fmt.Fprintf(w, "pzapp_listening_ports{port=\"%d\",proto=\"%s\",process=\"%s\",user=\"%s\"} %d\n",
+			l.port, escapeLabel(l.proto), escapeLabel(l.process), escapeLabel(l.user), counts[l])
+	}
+}
+
+// listenerCounts groups entries into series. A process listening on both
+// IPv4 and IPv6 counts twice, as it holds two sockets.
+func listenerCounts(entries []ports.Port) map[listenerLabels]int {
+	counts := make(map[listenerLabels]int)
+	for _, entry := range entries {
+		counts[listenerLabels{
+			port:    entry.Port,
+			proto:   strings.ToLower(entry.Protocol),
+			process: entry.Process,
+			user:    entry.User,
+		}]++
+	}
+	return counts
+}
+
+func writeMetric(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// escapeLabel escapes a label value as the exposition format requires.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,207 @@
+// Package audit records kill actions to an append-only sink, so shared hosts
+// can tell who terminated whose listener.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"portkiller/internal/ports"
+)
+
+// Outcomes recorded in Record.Outcome.
+const (
+	OutcomeTerminated = "terminated"
+	OutcomeFailed     = "failed"
+)
+
+// Sources recorded in Record.Source.
+const (
+	SourceTUI = "tui"
+	SourceCLI = "cli"
+	SourceAPI = "api"
+)
+
+// Record is one audited kill.
+type Record struct {
+	Time time.Time `json:"time"`
+	Host string    `json:"host,omitempty"`
+	// Actor is the user pzapp runs as; SudoUser is who invoked sudo, if
+	// anyone.
+	Actor    string `json:"actor"`
+	UID      int    `json:"uid"`
+	SudoUser string `json:"sudo_user,omitempty"`
+	Source   string `json:"source"`
+
+	// TargetHost is the remote machine the process ran on, if not Host.
+	TargetHost string `json:"target_host,omitempty"`
+	PID        int    `json:"pid"`
+	Process    string `json:"process,omitempty"`
+	Owner      string `json:"owner,omitempty"`
+	Protocol   string `json:"protocol,omitempty"`
+	Port       int    `json:"port,omitempty"`
+	Signal     string `json:"signal"`
+	Outcome    string `json:"outcome"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Sink stores audit records.
+type Sink interface {
+	Write(Record) error
+	Close() error
+}
+
+// Logger stamps kills with the invoking user and writes them to a sink. A
+// nil *Logger records nothing, so callers need not check whether auditing is
+// enabled.
+type Logger struct {
+	sink     Sink
+	source   string
+	host     string
+	actor    string
+	uid      int
+	sudoUser string
+}
+
+// New returns a Logger writing kills made from source to sink.
+func New(sink Sink, source string) *Logger {
+	l := &Logger{sink: sink, source: source, uid: os.Getuid(), sudoUser: os.Getenv("SUDO_USER")}
+	l.host, _ = os.Hostname()
+	if current, err := user.Current(); err == nil {
+		l.actor = current.Username
+	} else {
+		l.actor = strconv.Itoa(l.uid)
+	}
+	return l
+}
+
+// Record writes the outcome of sending signal to target. killErr is the
+// error Terminate returned, if any.
+func (l *Logger) Record(target ports.Port, signal string, killErr error) error {
+	if l == nil {
+		return nil
+	}
+	record := Record{
+		Time:       time.Now(),
+		Host:       l.host,
+		Actor:      l.actor,
+		UID:        l.uid,
+		SudoUser:   l.sudoUser,
+		Source:     l.source,
+		TargetHost: target.Host,
+		PID:        target.PID,
+		Process:    target.Process,
+		Owner:      target.User,
+		Protocol:   strings.ToLower(target.Protocol),
+		Port:       target.Port,
+		Signal:     strings.ToUpper(signal),
+		Outcome:    OutcomeTerminated,
+	}
+	if killErr != nil {
+		record.Outcome = OutcomeFailed
+		record.Error = killErr.Error()
+	}
+	if err := l.sink.Write(record); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
+}
+
+// Close releases the sink.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.sink.Close()
+}
+
+// DefaultPath returns the audit file used when none is configured, in
+// $XDG_STATE_HOME falling back to ~/.local/state.
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("locate state directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "pzapp", "audit.log"), nil
+}
+
+// fileSink appends records to a file as JSON lines.
+type fileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenFile opens path for appending, creating it and its directory if
+// needed.
+func OpenFile(path string) (Sink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create audit directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &fileSink{file: file}, nil
+}
+
+func (s *fileSink) Write(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encode record: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return nil
+}
+
+func (s *fileSink) Close() error {
+	return s.file.Close()
+}
+
+// formatSyslog renders r as key=value pairs, quoting values with spaces.
+func formatSyslog(r Record) string {
+	pairs := []struct{ key, value string }{
+		{"actor", r.Actor},
+		{"uid", strconv.Itoa(r.UID)},
+		{"sudo_user", r.SudoUser},
+		{"source", r.Source},
+		{"target_host", r.TargetHost},
+		{"pid", strconv.Itoa(r.PID)},
+		{"process", r.Process},
+		{"owner", r.Owner},
+		{"port", ""},
+		{"signal", r.Signal},
+		{"outcome", r.Outcome},
+		{"error", r.Error},
+	}
+	if r.Port > 0 {
+		pairs[8].value = r.Protocol + "/" + strconv.Itoa(r.Port)
+	}
+
+	parts := []string{"kill"}
+	for _, pair := range pairs {
+		if pair.value == "" {
+			continue
+		}
+		value := pair.value
+		if strings.ContainsAny(value, " \"=") {
+			value = strconv.Quote(value)
+		}
+		parts = append(parts, pair.key+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
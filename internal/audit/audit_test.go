@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"portkiller/internal/ports"
+)
+
+func TestFileSinkAppendsRecords(t *testing.T) {
+	t.Setenv("SUDO_USER", "alice")
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	sink, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	logger := New(sink, SourceTUI)
+
+	target := ports.Port{PID: 4521, Process: "node", User: "bob", Protocol: "TCP", Port: 3000}
+	if err := logger.Record(target, "term", nil); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := logger.Record(target, "KILL", errors.New("operation not permitted")); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines:\n%s", len(lines), data)
+	}
+
+	var first, second Record
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if first.PID != 4521 || first.Owner != "bob" || first.Signal != "TERM" || first.Outcome != OutcomeTerminated {
+		t.Fatalf("first = %+v", first)
+	}
+	if first.Actor == "" || first.UID != os.Getuid() || first.SudoUser != "alice" || first.Source != SourceTUI {
+		t.Fatalf("actor not recorded: %+v", first)
+	}
+	if second.Outcome != OutcomeFailed || second.Error != "operation not permitted" {
+		t.Fatalf("second = %+v", second)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("audit log mode = %v, %v", info.Mode(), err)
+	}
+}
+
+func TestNilLoggerRecordsNothing(t *testing.T) {
+	var logger *Logger
+	if err := logger.Record(ports.Port{PID: 1}, "TERM", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFormatSyslog(t *testing.T) {
+	got := formatSyslog(Record{
+		Actor: "alice", UID: 1000, Source: SourceCLI, PID: 4521, Process: "node", Owner: "bob",
+		Protocol: "tcp", Port: 3000, Signal: "TERM", Outcome: OutcomeFailed, Error: "operation not permitted",
+	})
+	want := `kill actor=alice uid=1000 source=cli pid=4521 process=node owner=bob port=tcp/3000 signal=TERM outcome=failed error="operation not permitted"`
+	if got != want {
+		t.Fatalf("formatSyslog =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package audit
+
+import "fmt"
+
+// OpenSyslog is not supported on non-Unix systems; use the file sink.
+func OpenSyslog() (Sink, error) {
+	return nil, fmt.Errorf("syslog audit sink is not supported on this platform")
+}
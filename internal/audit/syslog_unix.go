@@ -0,0 +1,39 @@
+//go:build unix
+
+package audit
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// syslogSink sends records to the local syslog daemon under the authpriv
+// facility, where other security events already go.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// OpenSyslog connects to the syslog daemon over its local socket.
+func OpenSyslog() (Sink, error) {
+	return dialSyslog("", "")
+}
+
+func dialSyslog(network, addr string) (Sink, error) {
+	writer, err := syslog.Dial(network, addr, syslog.LOG_AUTHPRIV|syslog.LOG_NOTICE, "pzapp")
+	if err != nil {
+		return nil, fmt.Errorf("connect to syslog: %w", err)
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) Write(r Record) error {
+	message := formatSyslog(r)
+	if r.Outcome == OutcomeFailed {
+		return s.writer.Warning(message)
+	}
+	return s.writer.Notice(message)
+}
+
+func (s *syslogSink) Close() error {
+	return s.writer.Close()
+}
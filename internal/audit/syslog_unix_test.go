@@ -0,0 +1,46 @@
+//go:build unix
+
+package audit
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"portkiller/internal/ports"
+)
+
+func TestSyslogSinkSendsToLocalSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets unavailable: %v", err)
+	}
+	defer conn.Close()
+
+	sink, err := dialSyslog("unixgram", socket)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	logger := New(sink, SourceTUI)
+	defer logger.Close()
+
+	if err := logger.Record(ports.Port{PID: 4521, User: "bob", Protocol: "tcp", Port: 3000}, "TERM", nil); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	message := string(buf[:n])
+	// <85> is authpriv (10) * 8 + notice (5).
+	if !strings.HasPrefix(message, "<85>") || !strings.Contains(message, "pzapp[") ||
+		!strings.Contains(message, "pid=4521 owner=bob port=tcp/3000 signal=TERM outcome=terminated") {
+		t.Fatalf("message = %q", message)
+	}
+}
@@ -0,0 +1,46 @@
+// Package clipboard copies text to the user's clipboard. Sequence builds an
+// OSC52 escape sequence, which terminals forward to the local clipboard even
+// over SSH, for the caller to emit with its own output; CopyNative also tries
+// the native clipboard when running on the desktop.
+package clipboard
+
+import (
+	"os"
+	"strings"
+
+	native "github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// writeNative is swapped out in tests.
+var writeNative = native.WriteAll
+
+// Sequence returns the OSC52 sequence that places text on the clipboard,
+// wrapped for tmux or screen when pzapp runs inside them. Programs that own
+// the terminal, like the TUI, emit it with their own output.
+func Sequence(text string) string {
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	return seq.String()
+}
+
+// CopyNative places text on the clipboard through the platform clipboard
+// tool. Over SSH that clipboard belongs to the remote host, so nothing is
+// done and the OSC52 sequence is the only route.
+func CopyNative(text string) error {
+	if remoteSession() {
+		return nil
+	}
+	return writeNative(text)
+}
+
+// remoteSession reports whether pzapp runs over SSH, where the native
+// clipboard belongs to the remote host and is of no use to the user.
+func remoteSession() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
@@ -0,0 +1,84 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func stubNative(t *testing.T, err error) *[]string {
+	t.Helper()
+	var copied []string
+	previous := writeNative
+	writeNative = func(text string) error {
+		copied = append(copied, text)
+		return err
+	}
+	t.Cleanup(func() { writeNative = previous })
+	return &copied
+}
+
+func clearSessionEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"TMUX", "TERM", "SSH_TTY", "SSH_CONNECTION"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestSequenceIsOSC52(t *testing.T) {
+	clearSessionEnv(t)
+
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("kill -TERM 4521")) + "\x07"
+	if got := Sequence("kill -TERM 4521"); got != want {
+		t.Fatalf("Sequence() = %q, want %q", got, want)
+	}
+}
+
+func TestSequenceWrapsForMultiplexers(t *testing.T) {
+	cases := map[string]struct {
+		env, value, prefix string
+	}{
+		"tmux":   {"TMUX", "/tmp/tmux-1000/default,1,0", "\x1bPtmux;"},
+		"screen": {"TERM", "screen-256color", "\x1bP"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clearSessionEnv(t)
+			t.Setenv(tc.env, tc.value)
+			if got := Sequence("3000"); !strings.HasPrefix(got, tc.prefix) {
+				t.Fatalf("Sequence() = %q, want a %s passthrough", got, name)
+			}
+		})
+	}
+}
+
+func TestCopyNative(t *testing.T) {
+	clearSessionEnv(t)
+	copied := stubNative(t, nil)
+
+	if err := CopyNative("kill -TERM 4521"); err != nil {
+		t.Fatalf("CopyNative() = %v", err)
+	}
+	if len(*copied) != 1 || (*copied)[0] != "kill -TERM 4521" {
+		t.Fatalf("native clipboard got %q", *copied)
+	}
+
+	stubNative(t, errors.New("no xclip"))
+	if err := CopyNative("3000"); err == nil {
+		t.Fatal("CopyNative() hid the clipboard tool's error")
+	}
+}
+
+func TestCopyNativeSkipsSSH(t *testing.T) {
+	clearSessionEnv(t)
+	t.Setenv("SSH_TTY", "/dev/pts/3")
+	copied := stubNative(t, errors.New("no display"))
+
+	if err := CopyNative("4521"); err != nil {
+		t.Fatalf("CopyNative() over SSH = %v", err)
+	}
+	if len(*copied) != 0 {
+		t.Fatalf("native clipboard used over SSH: %q", *copied)
+	}
+}
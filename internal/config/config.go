@@ -0,0 +1,558 @@
+// Package config loads the user's pzapp configuration file and merges it with
+// the built-in defaults.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"portkiller/internal/history"
+	"portkiller/internal/ports"
+	"portkiller/internal/query"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config is the effective pzapp configuration.
+type Config struct {
+	// Provider selects the port discovery backend: "lsof" or "mock".
+	Provider string `toml:"provider"`
+	// Host, when set, inspects and kills on that machine over ssh instead of
+	// this one: a host, user@host or ssh_config alias.
+	Host string `toml:"host,omitempty"`
+	// RefreshInterval reloads the port list periodically; zero disables it.
+	RefreshInterval time.Duration `toml:"refresh_interval"`
+	// ListTimeout bounds a single provider List call.
+	ListTimeout time.Duration `toml:"list_timeout"`
+	// ToastDuration is how long transient notifications stay on screen.
+	ToastDuration time.Duration `toml:"toast_duration"`
+	// Theme names the colour palette used by the TUI: one of BuiltinThemes or
+	// a key of Themes.
+	Theme string `toml:"theme"`
+	// ReduceMotion disables animated accents, matrix rain and glitch effects.
+	ReduceMotion bool `toml:"reduce_motion"`
+	// Mouse enables click, wheel and header-sort support. Turning it off
+	// restores the terminal's native text selection.
+	Mouse bool `toml:"mouse"`
+	// Columns lists the visible table columns in display order.
+	Columns []string `toml:"columns"`
+	// ColumnWidths overrides the width spec of individual columns.
+	ColumnWidths map[string]ColumnWidth `toml:"column_widths,omitempty"`
+
+	Filters     Filters     `toml:"filters"`
+	Kill        KillPolicy  `toml:"kill"`
+	Fingerprint Fingerprint `toml:"fingerprint"`
+	History     History     `toml:"history"`
+	Audit       Audit       `toml:"audit"`
+	Protection  Protection  `toml:"protection"`
+	SSH         SSH         `toml:"ssh"`
+	// Hosts are remote machines the TUI shows in tabs next to this one.
+	Hosts []RemoteHost `toml:"hosts,omitempty"`
+	// Services names ports in the service column, on top of the built-in
+	// table. Keys are "port" or "proto/port", e.g. "8080" or "udp/5353".
+	Services map[string]string `toml:"services,omitempty"`
+	// Keys maps an action name to the keys that trigger it.
+	Keys map[string][]string `toml:"keys"`
+	// Themes holds user-defined palettes, selectable by name via Theme.
+	Themes map[string]Palette `toml:"themes,omitempty"`
+}
+
+// ColumnWidth is the minimum and desired width of a table column. Columns
+// never shrink below Min; slack is handed out until each reaches Desired.
+type ColumnWidth struct {
+	Min     int `toml:"min,omitempty"`
+	Desired int `toml:"desired,omitempty"`
+}
+
+// Palette is a user-defined theme. Unset colours are inherited from Base.
+type Palette struct {
+	Base       string   `toml:"base"`
+	OnAccent   string   `toml:"on_accent,omitempty"`
+	Surface    string   `toml:"surface,omitempty"`
+	Panel      string   `toml:"panel,omitempty"`
+	Border     string   `toml:"border,omitempty"`
+	Text       string   `toml:"text,omitempty"`
+	TextMuted  string   `toml:"text_muted,omitempty"`
+	TextSubtle string   `toml:"text_subtle,omitempty"`
+	Accent     string   `toml:"accent,omitempty"`
+	Highlight  string   `toml:"highlight,omitempty"`
+	Success    string   `toml:"success,omitempty"`
+	Error      string   `toml:"error,omitempty"`
+	Warning    string   `toml:"warning,omitempty"`
+	Accents    []string `toml:"accents,omitempty"`
+}
+
+// BuiltinThemes lists the palettes compiled into the TUI.
+var BuiltinThemes = []string{"matrix", "tokyonight", "high-contrast", "monochrome"}
+
+// Filters are applied on startup until the user changes them in the TUI.
+type Filters struct {
+	Query        string `toml:"query"`
+	MineOnly     bool   `toml:"mine_only"`
+	HideSystem   bool   `toml:"hide_system"`
+	HideLoopback bool   `toml:"hide_loopback"`
+	HideUDP      bool   `toml:"hide_udp"`
+}
+
+// KillPolicy controls how processes are terminated.
+type KillPolicy struct {
+	// Signal is the first signal sent, e.g. "TERM" or "INT".
+	Signal string `toml:"signal"`
+	// Grace is how long to wait for the process to exit after Signal.
+	Grace time.Duration `toml:"grace"`
+	// Escalate sends SIGKILL if the process outlives Grace.
+	Escalate bool `toml:"escalate"`
+	// KillWait is how long to wait for the process to exit after SIGKILL.
+	KillWait time.Duration `toml:"kill_wait"`
+}
+
+// Fingerprint controls active protocol detection. It connects to every local
+// TCP listener, so it is off unless the user opts in.
+type Fingerprint struct {
+	Enabled bool `toml:"enabled"`
+	// Timeout bounds each connect and read while probing a socket.
+	Timeout time.Duration `toml:"timeout"`
+}
+
+// History controls the kill history log.
+type History struct {
+	Enabled bool `toml:"enabled"`
+	// Path overrides the history file location.
+	Path string `toml:"path,omitempty"`
+	// Env lists the environment variables recorded with each kill, as
+	// path.Match patterns such as "NODE_*".
+	Env []string `toml:"env"`
+}
+
+// HistoryPath returns the configured history file, or the default one in the
+// state directory.
+func (c Config) HistoryPath() (string, error) {
+	if c.History.Path != "" {
+		return c.History.Path, nil
+	}
+	return history.Path()
+}
+
+// Audit controls the append-only log of kill actions kept for shared hosts.
+type Audit struct {
+	Enabled bool `toml:"enabled"`
+	// Sink is "file" or "syslog".
+	Sink string `toml:"sink"`
+	// Path overrides the audit file location for the file sink.
+	Path string `toml:"path,omitempty"`
+}
+
+// SSH controls how remote hosts are reached.
+type SSH struct {
+	// Command is the ssh executable.
+	Command string `toml:"command"`
+	// Args are extra options for every ssh invocation, e.g. ["-p", "2222"].
+	Args []string `toml:"args,omitempty"`
+}
+
+// Remote returns the settings for reaching host over ssh.
+func (s SSH) Remote(host string) ports.Remote {
+	return ports.Remote{Target: host, SSH: s.Command, Args: append([]string(nil), s.Args...)}
+}
+
+// RemoteHost is a machine in the TUI's host switcher, reached over ssh with
+// the [ssh] settings.
+type RemoteHost struct {
+	// Target is what ssh connects to: a host, user@host or ssh_config alias.
+	Target string `toml:"target"`
+	// RefreshInterval and ListTimeout override the top-level settings for
+	// this host; zero keeps them.
+	RefreshInterval time.Duration `toml:"refresh_interval,omitempty"`
+	ListTimeout     time.Duration `toml:"list_timeout,omitempty"`
+}
+
+// ForHost returns the configuration for inspecting h: c with Host set and
+// h's overrides applied.
+func (c Config) ForHost(h RemoteHost) Config {
+	c.Host = h.Target
+	if h.RefreshInterval > 0 {
+		c.RefreshInterval = h.RefreshInterval
+	}
+	if h.ListTimeout > 0 {
+		c.ListTimeout = h.ListTimeout
+	}
+	return c
+}
+
+// Protection guards processes that should not be killed casually.
+type Protection struct {
+	// Defaults enables the built-in rules for init, sshd, dockerd and the
+	// like.
+	Defaults bool `toml:"defaults"`
+	// Rules are checked in addition to the defaults.
+	Rules []ProtectRule `toml:"rules,omitempty"`
+}
+
+// ProtectRule matches processes by name, user, port or executable path. Every
+// field that is set must match; strings are globs.
+type ProtectRule struct {
+	Process string `toml:"process,omitempty"`
+	User    string `toml:"user,omitempty"`
+	Port    int    `toml:"port,omitempty"`
+	Exe     string `toml:"exe,omitempty"`
+	// Action is "confirm" (type the process name to kill) or "refuse".
+	Action string `toml:"action,omitempty"`
+}
+
+// RuleSet returns the effective protection rules. Rules without an action
+// require confirmation.
+func (p Protection) RuleSet() []ports.ProtectRule {
+	var rules []ports.ProtectRule
+	if p.Defaults {
+		rules = ports.DefaultProtectRules()
+	}
+	for _, r := range p.Rules {
+		action := r.Action
+		if action == "" {
+			action = ports.ProtectConfirm
+		}
+		rules = append(rules, ports.ProtectRule{Process: r.Process, User: r.User, Port: r.Port, Exe: r.Exe, Action: action})
+	}
+	return rules
+}
+
+// AuditSinks lists the supported audit sinks.
+var AuditSinks = []string{"file", "syslog"}
+
+// ColumnNames lists the table columns that may appear in Config.Columns.
+var ColumnNames = []string{"proto", "port", "process", "pid", "user", "address", "state", "uptime", "cmdline", "container", "service", "unit", "host"}
+
+// defaultColumns are shown when the config does not list any.
+var defaultColumns = []string{"proto", "port", "service", "process", "pid", "user", "address", "unit"}
+
+// DefaultKeys returns the built-in key bindings, keyed by action name.
+func DefaultKeys() map[string][]string {
+	return map[string][]string{
+		"up":              {"k", "up"},
+		"down":            {"j", "down"},
+		"page_up":         {"left", "h", "pgup", "b", "u"},
+		"page_down":       {"right", "l", "pgdown", "f"},
+		"top":             {"home", "g"},
+		"bottom":          {"end", "G"},
+		"kill":            {"enter", "d"},
+		"restart":         {"R"},
+		"refresh":         {"r"},
+		"filter":          {"/"},
+		"query":           {":"},
+		"toggle_mine":     {"M"},
+		"toggle_system":   {"P"},
+		"toggle_loopback": {"L"},
+		"toggle_udp":      {"U"},
+		"back":            {"esc"},
+		"yank":            {"y"},
+		"open":            {"o"},
+		"probe":           {"p"},
+		"details":         {"i"},
+		"help":            {"?"},
+		"quit":            {"q", "ctrl+c"},
+		"confirm":         {"y", "Y", "enter"},
+		"cancel":          {"n", "N", "esc"},
+		"sudo":            {"s"},
+		"stop_unit":       {"S"},
+		"restart_unit":    {"R"},
+		"yank_pid":        {"p"},
+		"yank_port":       {"n"},
+		"yank_address":    {"a"},
+		"yank_url":        {"u"},
+		"yank_cmdline":    {"c"},
+		"yank_kill":       {"k"},
+		"history":         {"H"},
+		"relaunch":        {"enter", "r"},
+		"next_host":       {"tab"},
+		"prev_host":       {"shift+tab"},
+	}
+}
+
+// actionScopes places actions that are only live inside a modal or menu in
+// their own scope, so their keys may overlap with the list actions. Actions
+// not listed here belong to the list scope.
+var actionScopes = map[string]string{
+	"confirm":      "modal",
+	"cancel":       "modal",
+	"sudo":         "modal",
+	"stop_unit":    "modal",
+	"restart_unit": "modal",
+	"yank_pid":     "yank",
+	"yank_port":    "yank",
+	"yank_address": "yank",
+	"yank_url":     "yank",
+	"yank_cmdline": "yank",
+	"yank_kill":    "yank",
+	"relaunch":     "history",
+}
+
+// Default returns the configuration used when no file is present.
+func Default() Config {
+	policy := ports.DefaultKillPolicy()
+	return Config{
+		Provider:      "lsof",
+		ListTimeout:   2 * time.Second,
+		ToastDuration: 3 * time.Second,
+		Theme:         "matrix",
+		Mouse:         true,
+		Columns:       append([]string(nil), defaultColumns...),
+		Kill: KillPolicy{
+			Signal:   policy.Signal,
+			Grace:    policy.Grace,
+			Escalate: policy.Escalate,
+			KillWait: policy.KillWait,
+		},
+		Fingerprint: Fingerprint{Timeout: ports.DefaultFingerprintTimeout},
+		History:     History{Enabled: true, Env: append([]string(nil), history.DefaultEnv...)},
+		Audit:       Audit{Sink: "file"},
+		Protection:  Protection{Defaults: true},
+		SSH:         SSH{Command: "ssh"},
+		Keys:        DefaultKeys(),
+	}
+}
+
+// Path returns the default config file location, honouring $XDG_CONFIG_HOME
+// and falling back to ~/.config.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("locate config directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "pzapp", "config.toml"), nil
+}
+
+// Load reads the file at path over the defaults. A missing file is not an
+// error; unknown keys are.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("read config: %w", err)
+	}
+
+	meta, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		return cfg, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return cfg, fmt.Errorf("config %s: unknown keys: %s", path, strings.Join(keys, ", "))
+	}
+
+	cfg.Normalize()
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Normalize rewrites settings accepted in several spellings to the one the
+// rest of pzapp compares and displays, e.g. kill.signal "SIGINT" or "int"
+// to "INT". Invalid values are left for Validate to report.
+func (c *Config) Normalize() {
+	if name, err := ports.ParseSignal(c.Kill.Signal); err == nil {
+		c.Kill.Signal = name
+	}
+}
+
+// Validate reports the first invalid setting.
+func (c Config) Validate() error {
+	switch c.Provider {
+	case "lsof", "mock":
+	default:
+		return fmt.Errorf("provider: unknown provider %q", c.Provider)
+	}
+	if err := validHost(c.Host); err != nil {
+		return fmt.Errorf("host: %w", err)
+	}
+	if c.SSH.Command == "" {
+		return fmt.Errorf("ssh.command: must not be empty")
+	}
+	names := make(map[string]bool)
+	for i, h := range c.Hosts {
+		if h.Target == "" {
+			return fmt.Errorf("hosts[%d].target: must not be empty", i)
+		}
+		if err := validHost(h.Target); err != nil {
+			return fmt.Errorf("hosts[%d].target: %w", i, err)
+		}
+		name := c.SSH.Remote(h.Target).Name()
+		if names[name] {
+			return fmt.Errorf("hosts[%d].target: host %q listed twice", i, name)
+		}
+		names[name] = true
+		if h.RefreshInterval < 0 || h.ListTimeout < 0 {
+			return fmt.Errorf("hosts[%d]: durations must not be negative", i)
+		}
+	}
+	if c.RefreshInterval < 0 {
+		return fmt.Errorf("refresh_interval: must not be negative")
+	}
+	if c.ListTimeout <= 0 {
+		return fmt.Errorf("list_timeout: must be positive")
+	}
+	if c.ToastDuration <= 0 {
+		return fmt.Errorf("toast_duration: must be positive")
+	}
+	if len(c.Columns) == 0 {
+		return fmt.Errorf("columns: at least one column is required")
+	}
+	seen := make(map[string]bool)
+	for _, column := range c.Columns {
+		if !contains(ColumnNames, column) {
+			return fmt.Errorf("columns: unknown column %q (want one of %s)", column, strings.Join(ColumnNames, ", "))
+		}
+		if seen[column] {
+			return fmt.Errorf("columns: %q listed twice", column)
+		}
+		seen[column] = true
+	}
+	for column, width := range c.ColumnWidths {
+		if !contains(ColumnNames, column) {
+			return fmt.Errorf("column_widths.%s: unknown column", column)
+		}
+		if width.Min < 0 || width.Desired < 0 || (width.Desired > 0 && width.Desired < width.Min) {
+			return fmt.Errorf("column_widths.%s: want 0 <= min <= desired", column)
+		}
+	}
+	if _, custom := c.Themes[c.Theme]; !custom && !contains(BuiltinThemes, c.Theme) {
+		return fmt.Errorf("theme: unknown theme %q (want one of %s or a [themes] entry)", c.Theme, strings.Join(BuiltinThemes, ", "))
+	}
+	for name, palette := range c.Themes {
+		if contains(BuiltinThemes, name) {
+			return fmt.Errorf("themes.%s: cannot redefine a built-in theme", name)
+		}
+		if palette.Base != "" && !contains(BuiltinThemes, palette.Base) {
+			return fmt.Errorf("themes.%s.base: unknown built-in theme %q", name, palette.Base)
+		}
+	}
+	if _, err := query.Parse(c.Filters.Query); err != nil {
+		return fmt.Errorf("filters.query: %w", err)
+	}
+	if _, err := ports.ParseSignal(c.Kill.Signal); err != nil {
+		return fmt.Errorf("kill.signal: %w", err)
+	}
+	if c.Kill.Grace < 0 || c.Kill.KillWait < 0 {
+		return fmt.Errorf("kill: wait durations must not be negative")
+	}
+	if c.Fingerprint.Timeout <= 0 {
+		return fmt.Errorf("fingerprint.timeout: must be positive")
+	}
+	for _, pattern := range c.History.Env {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("history.env: bad pattern %q", pattern)
+		}
+	}
+	if !contains(AuditSinks, c.Audit.Sink) {
+		return fmt.Errorf("audit.sink: unknown sink %q (want one of %s)", c.Audit.Sink, strings.Join(AuditSinks, ", "))
+	}
+	if c.Audit.Path != "" && c.Audit.Sink != "file" {
+		return fmt.Errorf("audit.path: only used by the file sink")
+	}
+	for i, rule := range (Protection{Rules: c.Protection.Rules}).RuleSet() {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("protection.rules[%d]: %w", i, err)
+		}
+	}
+	for key, name := range c.Services {
+		if _, err := ports.ParseServiceKey(key); err != nil {
+			return fmt.Errorf("services: %w", err)
+		}
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("services.%s: name must not be empty", key)
+		}
+	}
+	return validateKeys(c.Keys)
+}
+
+func validateKeys(keys map[string][]string) error {
+	known := DefaultKeys()
+	actions := make([]string, 0, len(keys))
+	for action := range keys {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	owners := map[string]map[string]string{}
+	for _, action := range actions {
+		if _, ok := known[action]; !ok {
+			return fmt.Errorf("keys.%s: unknown action", action)
+		}
+		if len(keys[action]) == 0 {
+			return fmt.Errorf("keys.%s: at least one key is required", action)
+		}
+		scope, ok := owners[actionScopes[action]]
+		if !ok {
+			scope = map[string]string{}
+			owners[actionScopes[action]] = scope
+		}
+		for _, key := range keys[action] {
+			if other, taken := scope[key]; taken && other != action {
+				return fmt.Errorf("keys.%s: %q is already bound to %s", action, key, other)
+			}
+			scope[key] = action
+		}
+	}
+	return nil
+}
+
+// Toggles converts the default filter switches into query toggles.
+func (f Filters) Toggles() query.Toggles {
+	return query.Toggles{
+		MineOnly:     f.MineOnly,
+		HideSystem:   f.HideSystem,
+		HideLoopback: f.HideLoopback,
+		HideUDP:      f.HideUDP,
+	}
+}
+
+// Policy converts the kill settings into a ports.KillPolicy.
+func (k KillPolicy) Policy() ports.KillPolicy {
+	return ports.KillPolicy{
+		Signal:   k.Signal,
+		Grace:    k.Grace,
+		Escalate: k.Escalate,
+		KillWait: k.KillWait,
+	}
+}
+
+// Encode writes the configuration as TOML.
+func Encode(w io.Writer, c Config) error {
+	return toml.NewEncoder(w).Encode(c)
+}
+
+func contains(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
+
+// validHost rejects ssh targets that ssh would read as options or that
+// could not name a host.
+func validHost(host string) error {
+	if strings.HasPrefix(host, "-") || strings.ContainsAny(host, " \t\n") {
+		return fmt.Errorf("invalid ssh target %q", host)
+	}
+	return nil
+}
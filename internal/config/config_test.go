@@ -0,0 +1,227 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"portkiller/internal/ports"
+)
+
+func TestLoadMergesOverDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	raw := `
+provider = "mock"
+refresh_interval = "5s"
+mouse = false
+columns = ["port", "process"]
+
+[column_widths.process]
+min = 20
+desired = 40
+
+[filters]
+query = "port:3000-3999 user:!root"
+hide_udp = true
+
+[kill]
+signal = "SIGINT"
+grace = "2s"
+
+[keys]
+kill = ["x"]
+up = ["k"]
+
+[history]
+env = ["PATH", "NODE_*"]
+
+[audit]
+enabled = true
+sink = "syslog"
+
+[[protection.rules]]
+process = "openvpn"
+action = "refuse"
+
+[[protection.rules]]
+user = "postgres"
+port = 5432
+
+[services]
+"8080" = "api gateway"
+"udp/5353" = "avahi"
+
+[themes.dusk]
+base = "tokyonight"
+accent = "#ff9e64"
+`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	if cfg.Provider != "mock" || cfg.RefreshInterval != 5*time.Second {
+		t.Fatalf("top-level settings not applied: %+v", cfg)
+	}
+	if cfg.Services["8080"] != "api gateway" || cfg.Services["udp/5353"] != "avahi" {
+		t.Fatalf("services = %v", cfg.Services)
+	}
+	if !cfg.History.Enabled || strings.Join(cfg.History.Env, ",") != "PATH,NODE_*" {
+		t.Fatalf("history = %+v", cfg.History)
+	}
+	if !cfg.Audit.Enabled || cfg.Audit.Sink != "syslog" {
+		t.Fatalf("audit = %+v", cfg.Audit)
+	}
+	rules := cfg.Protection.RuleSet()
+	if len(rules) != len(ports.DefaultProtectRules())+2 || rules[len(rules)-1].Action != ports.ProtectConfirm ||
+		rules[len(rules)-2].Process != "openvpn" {
+		t.Fatalf("protection rules = %+v", rules)
+	}
+	if cfg.Mouse {
+		t.Fatalf("mouse = true, want false from file")
+	}
+	if cfg.ListTimeout != 2*time.Second || cfg.ToastDuration != 3*time.Second {
+		t.Fatalf("defaults lost: list_timeout=%s toast_duration=%s", cfg.ListTimeout, cfg.ToastDuration)
+	}
+	if strings.Join(cfg.Columns, ",") != "port,process" {
+		t.Fatalf("columns = %v", cfg.Columns)
+	}
+	if w := cfg.ColumnWidths["process"]; w.Min != 20 || w.Desired != 40 {
+		t.Fatalf("column_widths.process = %+v", w)
+	}
+	if !cfg.Filters.Toggles().HideUDP || cfg.Filters.Toggles().MineOnly {
+		t.Fatalf("filters = %+v", cfg.Filters)
+	}
+	if strings.Join(cfg.Keys["kill"], ",") != "x" || strings.Join(cfg.Keys["up"], ",") != "k" {
+		t.Fatalf("key overrides not applied: kill=%v up=%v", cfg.Keys["kill"], cfg.Keys["up"])
+	}
+	if strings.Join(cfg.Keys["refresh"], ",") != "r" {
+		t.Fatalf("default keys lost: refresh=%v", cfg.Keys["refresh"])
+	}
+	if cfg.Themes["dusk"].Base != "tokyonight" || cfg.Themes["dusk"].Accent != "#ff9e64" {
+		t.Fatalf("themes = %+v", cfg.Themes)
+	}
+	policy := cfg.Kill.Policy()
+	if policy.Signal != "INT" || policy.Grace != 2*time.Second || !policy.Escalate {
+		t.Fatalf("kill policy = %+v", policy)
+	}
+}
+
+func TestLoadMissingFileUsesDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "absent.toml"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Provider != Default().Provider {
+		t.Fatalf("expected defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadNormalizesSignal(t *testing.T) {
+	for _, spelling := range []string{"SIGINT", "sigint", " int "} {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		if err := os.WriteFile(path, []byte("[kill]\nsignal = \""+spelling+"\"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("load %q: %v", spelling, err)
+		}
+		if cfg.Kill.Signal != "INT" || cfg.Kill.Policy().Signal != "INT" {
+			t.Errorf("kill.signal %q loaded as %q, want INT", spelling, cfg.Kill.Signal)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	cases := map[string]string{
+		`colour = "red"`:                                     "unknown keys: colour",
+		`provider = "netstat"`:                               "unknown provider",
+		`columns = ["port", "vibes"]`:                        "unknown column",
+		`columns = ["port", "port"]`:                         "listed twice",
+		"[column_widths.vibes]\nmin = 4":                     "column_widths.vibes",
+		"[column_widths.port]\nmin = -1":                     "column_widths.port",
+		"[column_widths.port]\nmin = 9\ndesired = 4":         "column_widths.port",
+		"[filters]\nquery = \"port:abc\"":                    "filters.query",
+		"[kill]\nsignal = \"STOP\"":                          "kill.signal",
+		"[fingerprint]\ntimeout = \"0s\"":                    "fingerprint.timeout",
+		"[history]\nenv = [\"NODE_[\"]":                      "history.env",
+		"[audit]\nsink = \"journald\"":                       "audit.sink",
+		"[audit]\nsink = \"syslog\"\npath = \"/x\"":          "audit.path",
+		"[[protection.rules]]\naction = \"refuse\"":          "protection.rules[0]",
+		"[[protection.rules]]\nport = 22\naction = \"warn\"": "protection.rules[0]",
+		"[services]\nhttp = \"web\"":                         "services",
+		"[services]\n8080 = \"\"":                            "name must not be empty",
+		`refresh_interval = "-1s"`:                           "refresh_interval",
+		`list_timeout = "not-a-duration"`:                    "parse config",
+		"[keys]\nteleport = [\"t\"]":                         "unknown action",
+		"[keys]\nkill = []":                                  "at least one key",
+		"[keys]\nkill = [\"r\"]":                             "already bound",
+		"[keys]\nyank_pid = [\"n\"]":                         "already bound",
+		`theme = "solarized"`:                                "unknown theme",
+		`host = "-oProxyCommand=x"`:                          "host",
+		"[ssh]\ncommand = \"\"":                              "ssh.command",
+		"[[hosts]]\nrefresh_interval = \"5s\"":               "hosts[0].target",
+		`hosts = [{target = "a@vm"}, {target = "vm"}]`:       "listed twice",
+		`hosts = [{target = "vm", list_timeout = "-1s"}]`:    "hosts[0]",
+		"[themes.matrix]\naccent = \"#fff\"":                 "cannot redefine",
+		"[themes.x]\nbase = \"x\"":                           "unknown built-in theme",
+	}
+
+	for raw, want := range cases {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := Load(path)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Load(%q) error = %v, want mention of %q", raw, err, want)
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, Default()); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Fatalf("printed config does not load back: %v\n%s", err, buf.String())
+	}
+}
+
+func TestForHostAppliesOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	raw := "refresh_interval = \"10s\"\n[[hosts]]\ntarget = \"deploy@vm1\"\nlist_timeout = \"8s\"\n[[hosts]]\ntarget = \"vm2\"\nrefresh_interval = \"1m\"\n"
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.Hosts) != 2 {
+		t.Fatalf("hosts = %+v", cfg.Hosts)
+	}
+
+	vm1 := cfg.ForHost(cfg.Hosts[0])
+	if vm1.Host != "deploy@vm1" || vm1.ListTimeout != 8*time.Second || vm1.RefreshInterval != 10*time.Second {
+		t.Errorf("ForHost(vm1) = host %q, list_timeout %v, refresh_interval %v", vm1.Host, vm1.ListTimeout, vm1.RefreshInterval)
+	}
+	vm2 := cfg.ForHost(cfg.Hosts[1])
+	if vm2.Host != "vm2" || vm2.ListTimeout != cfg.ListTimeout || vm2.RefreshInterval != time.Minute {
+		t.Errorf("ForHost(vm2) = host %q, list_timeout %v, refresh_interval %v", vm2.Host, vm2.ListTimeout, vm2.RefreshInterval)
+	}
+}
@@ -0,0 +1,138 @@
+// Package history records killed processes so they can be inspected and
+// started again.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Results recorded in Entry.Result.
+const (
+	ResultTerminated = "terminated"
+	ResultFailed     = "failed"
+)
+
+// DefaultEnv lists the environment variables recorded with each kill unless
+// the config says otherwise. Secrets are deliberately left out.
+var DefaultEnv = []string{"PATH", "NODE_ENV", "PORT", "HOST", "VIRTUAL_ENV", "GOFLAGS", "RAILS_ENV", "FLASK_*", "DJANGO_SETTINGS_MODULE"}
+
+// Entry is one line of the history file.
+type Entry struct {
+	Time     time.Time         `json:"time"`
+	Protocol string            `json:"protocol"`
+	Port     int               `json:"port"`
+	PID      int               `json:"pid"`
+	Process  string            `json:"process"`
+	Cmdline  string            `json:"cmdline,omitempty"`
+	Argv     []string          `json:"argv,omitempty"`
+	Cwd      string            `json:"cwd,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+	Signal   string            `json:"signal"`
+	Result   string            `json:"result"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// Relaunchable reports whether enough was captured to start the command
+// again.
+func (e Entry) Relaunchable() bool {
+	return len(e.Argv) > 0
+}
+
+// Path returns the location of the history file, honouring $XDG_STATE_HOME
+// and falling back to ~/.local/state.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("locate state directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "pzapp", "history.jsonl"), nil
+}
+
+// Append adds e to the history file at path, creating it if needed. The
+// file is private to the user since it holds command lines and environment.
+func Append(path string, e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode history entry: %w", err)
+	}
+
+	f, err := open(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	return nil
+}
+
+// Touch creates the history file at path if it does not exist yet, so a
+// helper appending to it under sudo writes to a file the user still owns.
+func Touch(path string) error {
+	f, err := open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func open(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create state directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open history: %w", err)
+	}
+	return f, nil
+}
+
+// Load returns up to limit entries from the history file at path, newest
+// first. A missing file yields no entries; unreadable lines are skipped so a
+// torn write does not hide the rest of the history.
+func Load(path string, limit int) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+		if limit > 0 && len(entries) > limit {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
@@ -0,0 +1,99 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendLoadNewestFirst(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	path, err := Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	empty, err := Load(path, 10)
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("load without file = %v, %v", empty, err)
+	}
+
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	for i, process := range []string{"node", "vite", "redis-server"} {
+		entry := Entry{
+			Time:    start.Add(time.Duration(i) * time.Minute),
+			Port:    3000 + i,
+			PID:     100 + i,
+			Process: process,
+			Argv:    []string{process, "--port", "3000"},
+			Cwd:     "/srv/app",
+			Env:     map[string]string{"NODE_ENV": "development"},
+			Signal:  "TERM",
+			Result:  ResultTerminated,
+		}
+		if err := Append(path, entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("history file mode = %o, want 600", perm)
+	}
+
+	entries, err := Load(path, 2)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Process != "redis-server" || entries[1].Process != "vite" {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if !entries[0].Time.Equal(start.Add(2*time.Minute)) || entries[0].Env["NODE_ENV"] != "development" {
+		t.Fatalf("round trip lost fields: %+v", entries[0])
+	}
+	if !entries[0].Relaunchable() || (Entry{Cmdline: "node"}).Relaunchable() {
+		t.Fatal("Relaunchable should require a recorded argv")
+	}
+}
+
+func TestLoadSkipsTornLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	data := `{"process":"node","port":3000,"result":"terminated"}
+{"process":"vi
+{"process":"vite","port":5173,"result":"failed","error":"operation not permitted"}
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Load(path, 0)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Process != "vite" || entries[0].Error == "" || entries[1].Port != 3000 {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
+
+func TestTouchKeepsEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pzapp", "history.jsonl")
+	if err := Touch(path); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 || info.Mode().Perm() != 0o600 {
+		t.Fatalf("touched file = %v, %v; want an empty private file", info, err)
+	}
+	if err := Append(path, Entry{Process: "node", Signal: "TERM", Result: ResultTerminated}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Touch(path); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := Load(path, 0); err != nil || len(entries) != 1 {
+		t.Fatalf("entries after touching again = %v, %v", entries, err)
+	}
+}
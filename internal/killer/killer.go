@@ -0,0 +1,165 @@
+// Package killer terminates processes on behalf of the TUI and the CLI,
+// enforcing protection rules and recording every kill to the history and
+// audit log.
+package killer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"portkiller/internal/audit"
+	"portkiller/internal/config"
+	"portkiller/internal/history"
+	"portkiller/internal/ports"
+)
+
+// snapshotTimeout bounds reading a process's details before a kill.
+const snapshotTimeout = time.Second
+
+// ProtectedError is returned when a protection rule blocks a kill.
+type ProtectedError struct {
+	Target ports.Port
+}
+
+func (e *ProtectedError) Error() string {
+	if e.Target.Protection == ports.ProtectRefuse {
+		return fmt.Sprintf("%s (PID %d) is protected by %s; refusing to kill it", e.Target.Process, e.Target.PID, e.Target.ProtectedBy)
+	}
+	return fmt.Sprintf("%s (PID %d) is protected by %s; confirm to kill it", e.Target.Process, e.Target.PID, e.Target.ProtectedBy)
+}
+
+// Killer terminates processes with a policy and records the outcome.
+type Killer struct {
+	Policy ports.KillPolicy
+	// History records kills for relaunching when Enabled.
+	History config.History
+	// HistoryPath is the history file; it is only used when History is
+	// enabled.
+	HistoryPath string
+	// Audit records kills for accountability; nil disables it.
+	Audit *audit.Logger
+	// Remotes maps ports.Port.Host to the machine its processes are killed
+	// on over ssh.
+	Remotes map[string]ports.Remote
+	// terminate is ports.TerminateWith; tests replace it.
+	terminate func(pid int, policy ports.KillPolicy) error
+}
+
+// New returns a Killer using the kill policy and history settings of cfg.
+func New(cfg config.Config, auditor *audit.Logger) (*Killer, error) {
+	k := &Killer{Policy: cfg.Kill.Policy(), History: cfg.History, Audit: auditor}
+	if cfg.History.Enabled {
+		path, err := cfg.HistoryPath()
+		if err != nil {
+			return nil, err
+		}
+		k.HistoryPath = path
+	}
+	targets := make([]string, 0, len(cfg.Hosts)+1)
+	if cfg.Host != "" {
+		targets = append(targets, cfg.Host)
+	}
+	for _, h := range cfg.Hosts {
+		targets = append(targets, h.Target)
+	}
+	if len(targets) > 0 {
+		k.Remotes = make(map[string]ports.Remote, len(targets))
+	}
+	for _, target := range targets {
+		remote := cfg.SSH.Remote(target)
+		k.Remotes[remote.Name()] = remote
+	}
+	return k, nil
+}
+
+// Result is the outcome of a kill. HistoryErr and AuditErr report failures
+// to record it; the kill itself may still have succeeded.
+type Result struct {
+	Err        error
+	HistoryErr error
+	AuditErr   error
+}
+
+// Kill terminates target's process. Processes protected with
+// ports.ProtectConfirm are only killed when confirmed is set, and those
+// protected with ports.ProtectRefuse never are; blocked kills return a
+// *ProtectedError without being recorded.
+func (k *Killer) Kill(target ports.Port, confirmed bool) Result {
+	if target.Hidden || target.PID <= 0 {
+		return Result{Err: fmt.Errorf("the owner of %s port %d is hidden; run pzapp as root to kill it", target.Protocol, target.Port)}
+	}
+	switch target.Protection {
+	case ports.ProtectRefuse:
+		return Result{Err: &ProtectedError{Target: target}}
+	case ports.ProtectConfirm:
+		if !confirmed {
+			return Result{Err: &ProtectedError{Target: target}}
+		}
+	}
+
+	terminate := k.terminate
+	if terminate == nil {
+		terminate = ports.TerminateWith
+	}
+	if target.Host != "" {
+		remote, ok := k.Remotes[target.Host]
+		if !ok {
+			return Result{Err: fmt.Errorf("no ssh settings for host %q", target.Host)}
+		}
+		terminate = remote.Terminate
+	}
+
+	// The command line and working directory are gone once the process
+	// exits, so they are read first.
+	snap := k.snapshot(target)
+	err := terminate(target.PID, k.Policy)
+	return Result{
+		Err:        err,
+		HistoryErr: k.record(target, snap, err),
+		AuditErr:   k.Audit.Record(target, k.Policy.Signal, err),
+	}
+}
+
+// snapshot captures what is needed to relaunch target, falling back to the
+// listed command line where /proc is unavailable. The fallback splits on
+// whitespace, so quoted arguments may not survive it.
+func (k *Killer) snapshot(target ports.Port) ports.ProcessSnapshot {
+	if !k.History.Enabled || target.Host != "" {
+		return ports.ProcessSnapshot{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	snap, _ := ports.SnapshotProcess(ctx, target.PID, k.History.Env)
+	if len(snap.Argv) == 0 {
+		snap.Argv = strings.Fields(target.Cmdline)
+	}
+	return snap
+}
+
+// record appends the outcome of a kill to the history file. Remote kills are
+// left out, since history relaunches run on this machine.
+func (k *Killer) record(target ports.Port, snap ports.ProcessSnapshot, killErr error) error {
+	if !k.History.Enabled || target.Host != "" {
+		return nil
+	}
+	entry := history.Entry{
+		Time:     time.Now(),
+		Protocol: target.Protocol,
+		Port:     target.Port,
+		PID:      target.PID,
+		Process:  target.Process,
+		Cmdline:  target.Cmdline,
+		Argv:     snap.Argv,
+		Cwd:      snap.Cwd,
+		Env:      snap.Env,
+		Signal:   strings.ToUpper(k.Policy.Signal),
+		Result:   history.ResultTerminated,
+	}
+	if killErr != nil {
+		entry.Result = history.ResultFailed
+		entry.Error = killErr.Error()
+	}
+	return history.Append(k.HistoryPath, entry)
+}
@@ -0,0 +1,150 @@
+package killer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"portkiller/internal/audit"
+	"portkiller/internal/config"
+	"portkiller/internal/history"
+	"portkiller/internal/ports"
+)
+
+func newTestKiller(t *testing.T, terminateErr error) (*Killer, *[]int) {
+	t.Helper()
+	var killed []int
+	k := &Killer{
+		Policy:      ports.DefaultKillPolicy(),
+		History:     config.History{Enabled: true},
+		HistoryPath: filepath.Join(t.TempDir(), "history.jsonl"),
+		terminate: func(pid int, _ ports.KillPolicy) error {
+			killed = append(killed, pid)
+			return terminateErr
+		},
+	}
+	return k, &killed
+}
+
+func TestKillRespectsProtection(t *testing.T) {
+	k, killed := newTestKiller(t, nil)
+	refused := ports.Port{PID: 1, Process: "systemd", Protection: ports.ProtectRefuse, ProtectedBy: "process systemd"}
+	guarded := ports.Port{PID: 22, Process: "sshd", Protection: ports.ProtectConfirm, ProtectedBy: "process sshd"}
+
+	var protected *ProtectedError
+	if result := k.Kill(refused, true); !errors.As(result.Err, &protected) {
+		t.Fatalf("Kill(refused) = %v, want a *ProtectedError", result.Err)
+	}
+	if result := k.Kill(guarded, false); !errors.As(result.Err, &protected) {
+		t.Fatalf("Kill(guarded, unconfirmed) = %v, want a *ProtectedError", result.Err)
+	}
+	if len(*killed) != 0 {
+		t.Fatalf("blocked kills signalled %v", *killed)
+	}
+	if result := k.Kill(guarded, true); result.Err != nil {
+		t.Fatalf("Kill(guarded, confirmed) = %v", result.Err)
+	}
+	if len(*killed) != 1 || (*killed)[0] != 22 {
+		t.Fatalf("killed %v, want [22]", *killed)
+	}
+
+	entries, err := history.Load(k.HistoryPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].PID != 22 {
+		t.Fatalf("history = %+v, want only the confirmed kill", entries)
+	}
+}
+
+func TestKillRecordsFailures(t *testing.T) {
+	denied := &ports.PermissionError{PID: 42, Signal: "TERM", Err: os.ErrPermission}
+	k, _ := newTestKiller(t, denied)
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := audit.OpenFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k.Audit = audit.New(sink, audit.SourceCLI)
+	defer k.Audit.Close()
+
+	result := k.Kill(ports.Port{PID: 42, Process: "postgres", Protocol: "tcp", Port: 5432}, false)
+	var permErr *ports.PermissionError
+	if !errors.As(result.Err, &permErr) {
+		t.Fatalf("Kill() = %v, want the *PermissionError", result.Err)
+	}
+	if result.HistoryErr != nil || result.AuditErr != nil {
+		t.Fatalf("recording failed: %v, %v", result.HistoryErr, result.AuditErr)
+	}
+
+	entries, err := history.Load(k.HistoryPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Result != history.ResultFailed || !strings.Contains(entries[0].Error, "not permitted") {
+		t.Fatalf("history = %+v", entries)
+	}
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"outcome":"failed"`) || !strings.Contains(string(data), `"source":"cli"`) {
+		t.Fatalf("audit log = %s", data)
+	}
+}
+
+func TestKillRunsRemoteKillsOverSSH(t *testing.T) {
+	dir := t.TempDir()
+	scriptLog := filepath.Join(dir, "script")
+	ssh := "#!/bin/sh\nfor last; do :; done\nprintf '%s' \"$last\" > " + scriptLog + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(ssh), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	k, killed := newTestKiller(t, nil)
+	k.Remotes = map[string]ports.Remote{"staging": {Target: "deploy@staging"}}
+	auditPath := filepath.Join(dir, "audit.log")
+	sink, err := audit.OpenFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k.Audit = audit.New(sink, audit.SourceTUI)
+	defer k.Audit.Close()
+
+	if result := k.Kill(ports.Port{PID: 4521, Process: "node", Host: "staging"}, false); result.Err != nil {
+		t.Fatalf("Kill(remote) = %v", result.Err)
+	}
+	if len(*killed) != 0 {
+		t.Fatalf("remote kill signalled local PIDs %v", *killed)
+	}
+	if script, _ := os.ReadFile(scriptLog); !strings.Contains(string(script), "kill -s TERM 4521") {
+		t.Fatalf("remote script = %q", script)
+	}
+	if entries, _ := history.Load(k.HistoryPath, 0); len(entries) != 0 {
+		t.Fatalf("remote kill recorded history %+v", entries)
+	}
+	if data, _ := os.ReadFile(auditPath); !strings.Contains(string(data), `"target_host":"staging"`) {
+		t.Fatalf("audit log = %s", data)
+	}
+
+	if result := k.Kill(ports.Port{PID: 1, Host: "prod"}, false); result.Err == nil {
+		t.Fatal("Kill() on a host without ssh settings succeeded")
+	}
+}
+
+func TestNewKnowsConfiguredHosts(t *testing.T) {
+	cfg := config.Default()
+	cfg.History.Enabled = false
+	cfg.SSH.Args = []string{"-p", "2222"}
+	cfg.Hosts = []config.RemoteHost{{Target: "vscode@devcontainer"}, {Target: "vm2"}}
+	k, err := New(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(k.Remotes) != 2 || k.Remotes["devcontainer"].Target != "vscode@devcontainer" || k.Remotes["vm2"].Args[1] != "2222" {
+		t.Fatalf("Remotes = %+v", k.Remotes)
+	}
+}
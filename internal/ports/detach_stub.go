@@ -0,0 +1,8 @@
+//go:build !unix
+
+package ports
+
+import "os/exec"
+
+// detach is a no-op where sessions are not supported.
+func detach(cmd *exec.Cmd) {}
@@ -0,0 +1,322 @@
+package ports
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Application protocols reported in Port.AppProtocol.
+const (
+	ProtoHTTP     = "HTTP"
+	ProtoHTTPS    = "HTTPS"
+	ProtoHTTP2    = "HTTP2"
+	ProtoTLS      = "TLS"
+	ProtoSSH      = "SSH"
+	ProtoRedis    = "REDIS"
+	ProtoPostgres = "POSTGRES"
+	ProtoMySQL    = "MYSQL"
+)
+
+// DefaultFingerprintTimeout bounds each read while fingerprinting a socket.
+const DefaultFingerprintTimeout = 300 * time.Millisecond
+
+// Fingerprinter connects to local TCP listeners and identifies the
+// application protocol they speak. Results are cached per process and port,
+// so a listener is only probed once; FingerprintProvider drops them once the
+// listener is gone.
+type Fingerprinter struct {
+	// Timeout bounds each connect and read; a silent listener costs a few
+	// multiples of it.
+	Timeout time.Duration
+	// Concurrency caps how many sockets are probed at once.
+	Concurrency int
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// NewFingerprinter returns a Fingerprinter with the given per-step timeout.
+func NewFingerprinter(timeout time.Duration) *Fingerprinter {
+	if timeout <= 0 {
+		timeout = DefaultFingerprintTimeout
+	}
+	return &Fingerprinter{Timeout: timeout, Concurrency: 32, cache: make(map[string]string)}
+}
+
+// Annotate sets AppProtocol on every reachable TCP listener in entries.
+// Sockets that could not be identified before ctx expired are left untouched
+// and retried on the next call.
+func (f *Fingerprinter) Annotate(ctx context.Context, entries []Port) {
+	limit := make(chan struct{}, max(1, f.Concurrency))
+	var wg sync.WaitGroup
+	for i := range entries {
+		entry := &entries[i]
+		if !entry.IsTCPListener() || !entry.Reachable() || entry.AppProtocol != "" {
+			continue
+		}
+		key := fingerprintKey(*entry)
+		if proto, ok := f.cached(key); ok {
+			entry.AppProtocol = proto
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case limit <- struct{}{}:
+				defer func() { <-limit }()
+			case <-ctx.Done():
+				return
+			}
+			proto, err := f.Identify(ctx, entry.DialHost(), entry.Port)
+			if err != nil {
+				return
+			}
+			entry.AppProtocol = proto
+			f.store(key, proto)
+		}()
+	}
+	wg.Wait()
+}
+
+// Identify returns the protocol spoken on host:port, or "" when none of the
+// known protocols answered. An error means the probe was cut short by ctx or
+// the port refused connections, so the result should not be trusted.
+func (f *Fingerprinter) Identify(ctx context.Context, host string, port int) (string, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+
+	// Server-first protocols greet as soon as the connection is accepted.
+	banner, err := f.exchange(ctx, addr, nil)
+	if err != nil {
+		return "", err
+	}
+	if proto := matchBanner(banner); proto != "" {
+		return proto, nil
+	}
+	if len(banner) > 0 {
+		return "", nil
+	}
+
+	// TLS goes first: TLS servers answer plain-text probes with an HTTP error.
+	if proto, err := f.tls(ctx, addr); err != nil || proto != "" {
+		return proto, err
+	}
+
+	// An HTTP/2 preface is answered with SETTINGS by h2c servers (gRPC) and
+	// with a 4xx/5xx response by HTTP/1 servers.
+	reply, err := f.exchange(ctx, addr, http2Preface)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case bytes.HasPrefix(reply, []byte("HTTP/")):
+		return ProtoHTTP, nil
+	case isSettingsFrame(reply):
+		return ProtoHTTP2, nil
+	}
+
+	reply, err = f.exchange(ctx, addr, []byte("PING\r\n"))
+	if err != nil {
+		return "", err
+	}
+	if bytes.HasPrefix(reply, []byte("+PONG")) || bytes.HasPrefix(reply, []byte("-NOAUTH")) {
+		return ProtoRedis, nil
+	}
+
+	reply, err = f.exchange(ctx, addr, postgresSSLRequest)
+	if err != nil {
+		return "", err
+	}
+	if len(reply) == 1 && (reply[0] == 'S' || reply[0] == 'N') {
+		return ProtoPostgres, nil
+	}
+	return "", nil
+}
+
+// exchange dials addr, optionally writes payload and returns whatever the
+// server sends before the read times out or the connection closes.
+func (f *Fingerprinter) exchange(ctx context.Context, addr string, payload []byte) ([]byte, error) {
+	conn, err := f.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if payload != nil {
+		if _, err := conn.Write(payload); err != nil {
+			return nil, nil
+		}
+	}
+
+	buf := make([]byte, 512)
+	n, err := conn.Read(buf)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return buf[:n], nil
+}
+
+// tls attempts a handshake, reporting HTTPS when the server negotiated an
+// HTTP protocol via ALPN.
+func (f *Fingerprinter) tls(ctx context.Context, addr string) (string, error) {
+	conn, err := f.dial(ctx, addr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	client := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"h2", "http/1.1"}})
+	if err := client.Handshake(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", nil
+	}
+	switch client.ConnectionState().NegotiatedProtocol {
+	case "h2", "http/1.1":
+		return ProtoHTTPS, nil
+	}
+	return ProtoTLS, nil
+}
+
+func (f *Fingerprinter) dial(ctx context.Context, addr string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: f.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(f.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (f *Fingerprinter) cached(key string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	proto, ok := f.cache[key]
+	return proto, ok
+}
+
+func (f *Fingerprinter) store(key, proto string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cache[key] = proto
+}
+
+// prune forgets the results for sockets missing from entries, so the cache
+// does not grow with every process that ever listened.
+func (f *Fingerprinter) prune(entries []Port) {
+	live := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		live[fingerprintKey(entry)] = true
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key := range f.cache {
+		if !live[key] {
+			delete(f.cache, key)
+		}
+	}
+}
+
+func fingerprintKey(p Port) string {
+	return strconv.Itoa(p.PID) + "/" + strconv.Itoa(p.Port)
+}
+
+var (
+	http2Preface = append([]byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"),
+		0, 0, 0, 0x4, 0, 0, 0, 0, 0) // empty SETTINGS frame
+	postgresSSLRequest = binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, 8), 80877103)
+)
+
+// matchBanner recognises the greeting of server-first protocols.
+func matchBanner(banner []byte) string {
+	switch {
+	case bytes.HasPrefix(banner, []byte("SSH-")):
+		return ProtoSSH
+	case isMySQLGreeting(banner):
+		return ProtoMySQL
+	}
+	return ""
+}
+
+// isMySQLGreeting checks for a MySQL packet with sequence 0 carrying either a
+// protocol 10 handshake or an error (e.g. host not allowed). The read may
+// have stopped short of the full packet.
+func isMySQLGreeting(b []byte) bool {
+	if len(b) < 5 {
+		return false
+	}
+	length := int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+	return b[3] == 0 && length < 1024 && length+4 >= len(b) && (b[4] == 0x0a || b[4] == 0xff)
+}
+
+// isSettingsFrame reports whether b starts with an HTTP/2 SETTINGS frame on
+// stream zero.
+func isSettingsFrame(b []byte) bool {
+	return len(b) >= 9 && b[3] == 0x4 && binary.BigEndian.Uint32(b[5:9])&0x7fffffff == 0
+}
+
+// IsTCPListener reports whether p is a listening TCP socket.
+func (p Port) IsTCPListener() bool {
+	if !strings.HasPrefix(strings.ToLower(p.Protocol), "tcp") {
+		return false
+	}
+	state := strings.ToLower(p.State)
+	return state == "" || strings.HasPrefix(state, "listen")
+}
+
+// DialHost is the host used to reach p from this machine; wildcard binds are
+// reached through loopback, or through the host name on remote hosts, where
+// loopback binds are not reachable at all.
+func (p Port) DialHost() string {
+	if p.Host != "" {
+		address := strings.Trim(p.Address, "[]")
+		if ip := net.ParseIP(address); address == "" || address == "*" || (ip != nil && (ip.IsUnspecified() || ip.IsLoopback())) {
+			return p.Host
+		}
+		return address
+	}
+	switch p.Address {
+	case "", "*", "0.0.0.0":
+		return "127.0.0.1"
+	case "::", "[::]":
+		return "::1"
+	}
+	return strings.Trim(p.Address, "[]")
+}
+
+// FingerprintProvider wraps a Provider and fingerprints the listeners it
+// returns.
+type FingerprintProvider struct {
+	Provider
+	Fingerprinter *Fingerprinter
+}
+
+// List returns the wrapped provider's ports with AppProtocol filled in.
+// Cached results for sockets no longer listed are dropped.
+func (p FingerprintProvider) List(ctx context.Context) ([]Port, error) {
+	entries, err := p.Provider.List(ctx)
+	if err != nil {
+		return entries, err
+	}
+	p.Fingerprinter.Annotate(ctx, entries)
+	p.Fingerprinter.prune(entries)
+	return entries, nil
+}
+
+var _ Provider = FingerprintProvider{}
@@ -0,0 +1,207 @@
+package ports
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServer accepts loopback connections and hands each to handle.
+func fakeServer(t *testing.T, handle func(net.Conn)) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(2 * time.Second))
+				handle(conn)
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// respondTo waits for a request starting with prefix and writes reply.
+func respondTo(prefix, reply []byte) func(net.Conn) {
+	return func(conn net.Conn) {
+		buf := make([]byte, len(prefix))
+		if _, err := io.ReadFull(conn, buf); err != nil || !bytes.Equal(buf, prefix) {
+			return
+		}
+		conn.Write(reply)
+	}
+}
+
+func serverPort(t *testing.T, srv *httptest.Server) int {
+	t.Helper()
+	return srv.Listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestFingerprinterIdentify(t *testing.T) {
+	httpSrv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer httpSrv.Close()
+
+	tlsSrv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	tlsSrv.EnableHTTP2 = true
+	tlsSrv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsSrv.StartTLS()
+	defer tlsSrv.Close()
+
+	mysqlGreeting := append([]byte{0x4a, 0, 0, 0, 0x0a}, []byte("8.0.36\x00")...)
+	mysqlGreeting = append(mysqlGreeting, make([]byte, 0x4a-len(mysqlGreeting)+4)...)
+
+	cases := []struct {
+		name string
+		port int
+		want string
+	}{
+		{"http", serverPort(t, httpSrv), ProtoHTTP},
+		{"https", serverPort(t, tlsSrv), ProtoHTTPS},
+		{"ssh", fakeServer(t, func(conn net.Conn) {
+			conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+		}), ProtoSSH},
+		{"mysql", fakeServer(t, func(conn net.Conn) {
+			conn.Write(mysqlGreeting)
+		}), ProtoMySQL},
+		{"redis", fakeServer(t, respondTo([]byte("PING\r\n"), []byte("+PONG\r\n"))), ProtoRedis},
+		{"redis with auth", fakeServer(t, respondTo([]byte("PING\r\n"), []byte("-NOAUTH Authentication required.\r\n"))), ProtoRedis},
+		{"postgres", fakeServer(t, respondTo(postgresSSLRequest, []byte("N"))), ProtoPostgres},
+		{"grpc", fakeServer(t, respondTo(http2Preface[:24], []byte{0, 0, 6, 0x4, 0, 0, 0, 0, 0, 0, 0x3, 0, 0, 0, 0x64})), ProtoHTTP2},
+		{"plain tls", fakeServer(t, tlsOnly(t, tlsSrv)), ProtoTLS},
+		{"silent", fakeServer(t, func(conn net.Conn) {
+			io.Copy(io.Discard, conn)
+		}), ""},
+		{"unknown banner", fakeServer(t, func(conn net.Conn) {
+			conn.Write([]byte("220 mail.example.com ESMTP\r\n"))
+		}), ""},
+	}
+
+	f := NewFingerprinter(200 * time.Millisecond)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := f.Identify(context.Background(), "127.0.0.1", tc.port)
+			if err != nil {
+				t.Fatalf("identify: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("Identify = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// tlsOnly serves a TLS handshake without ALPN, using the test server's
+// certificate.
+func tlsOnly(t *testing.T, srv *httptest.Server) func(net.Conn) {
+	t.Helper()
+	config := srv.TLS.Clone()
+	config.NextProtos = nil
+	return func(conn net.Conn) {
+		tlsConn := tls.Server(conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		bufio.NewReader(tlsConn).ReadByte()
+	}
+}
+
+func TestFingerprinterAnnotateCachesAndSkipsNonListeners(t *testing.T) {
+	accepted := make(chan struct{}, 16)
+	port := fakeServer(t, func(conn net.Conn) {
+		accepted <- struct{}{}
+		conn.Write([]byte("SSH-2.0-test\r\n"))
+	})
+
+	entries := []Port{
+		{PID: 10, Protocol: "TCP", Port: port, Address: "127.0.0.1", State: "LISTEN"},
+		{PID: 10, Protocol: "UDP", Port: port, Address: "127.0.0.1"},
+		{PID: 10, Protocol: "TCP", Port: port, Address: "127.0.0.1", State: "ESTABLISHED"},
+		// A container's listener on the same port number is somewhere else.
+		{PID: 20, Protocol: "TCP", Port: port, Address: "*", State: "LISTEN", NetNS: "net:[4026532451]"},
+	}
+	f := NewFingerprinter(200 * time.Millisecond)
+	f.Annotate(context.Background(), entries)
+
+	if entries[0].AppProtocol != ProtoSSH {
+		t.Fatalf("listener AppProtocol = %q", entries[0].AppProtocol)
+	}
+	if entries[1].AppProtocol != "" || entries[2].AppProtocol != "" || entries[3].AppProtocol != "" {
+		t.Fatalf("non-listeners or unreachable listeners annotated: %+v", entries[1:])
+	}
+	if len(accepted) != 1 {
+		t.Fatalf("accepted %d connections, want 1", len(accepted))
+	}
+
+	again := []Port{{PID: 10, Protocol: "TCP", Port: port, Address: "0.0.0.0", State: "LISTEN"}}
+	f.Annotate(context.Background(), again)
+	if again[0].AppProtocol != ProtoSSH || len(accepted) != 1 {
+		t.Fatalf("cache miss: AppProtocol=%q accepted=%d", again[0].AppProtocol, len(accepted))
+	}
+}
+
+func TestFingerprintProviderAnnotatesList(t *testing.T) {
+	port := fakeServer(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-2.0-test\r\n"))
+	})
+	inner := staticProvider{{PID: 1, Protocol: "TCP", Port: port, Address: "*", State: "LISTEN"}}
+
+	provider := FingerprintProvider{Provider: inner, Fingerprinter: NewFingerprinter(200 * time.Millisecond)}
+	entries, err := provider.List(context.Background())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if entries[0].AppProtocol != ProtoSSH {
+		t.Fatalf("AppProtocol = %q", entries[0].AppProtocol)
+	}
+}
+
+func TestFingerprintProviderPrunesGoneListeners(t *testing.T) {
+	port := fakeServer(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-2.0-test\r\n"))
+	})
+	f := NewFingerprinter(200 * time.Millisecond)
+	listings := []staticProvider{
+		{{PID: 1, Protocol: "TCP", Port: port, Address: "*", State: "LISTEN"}},
+		// PID 1 exited and PID 2 took over the port.
+		{{PID: 2, Protocol: "TCP", Port: port, Address: "*", State: "LISTEN"}},
+		{},
+	}
+	want := [][]string{{"1/" + strconv.Itoa(port)}, {"2/" + strconv.Itoa(port)}, nil}
+	for i, inner := range listings {
+		if _, err := (FingerprintProvider{Provider: inner, Fingerprinter: f}).List(context.Background()); err != nil {
+			t.Fatalf("list %d: %v", i, err)
+		}
+		var keys []string
+		for key := range f.cache {
+			keys = append(keys, key)
+		}
+		if strings.Join(keys, ",") != strings.Join(want[i], ",") {
+			t.Errorf("after list %d the cache holds %v, want %v", i, keys, want[i])
+		}
+	}
+}
+
+type staticProvider []Port
+
+func (s staticProvider) List(context.Context) ([]Port, error) {
+	return append([]Port(nil), s...), nil
+}
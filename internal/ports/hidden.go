@@ -0,0 +1,208 @@
+package ports
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HiddenOwner is the process name given to sockets whose owner could not be
+// identified.
+const HiddenOwner = "owner hidden (needs root)"
+
+// procNetTables are the socket tables read for hidden sockets, with the
+// protocol they list.
+var procNetTables = []struct {
+	file     string
+	protocol string
+}{
+	{"tcp", "tcp"},
+	{"tcp6", "tcp"},
+	{"udp", "udp"},
+	{"udp6", "udp"},
+}
+
+// tcpListen is the TCP_LISTEN state in /proc/net/tcp.
+const tcpListen = "0A"
+
+// procSocket is one line of a /proc/net socket table.
+type procSocket struct {
+	Port
+	inode string
+}
+
+// HiddenSockets returns the listening sockets in /proc/net whose inode is
+// not held by any process this user can inspect. Without root lsof never
+// reports them, so they are returned as Hidden rows with PID 0, owned by the
+// socket's UID. Systems without /proc yield nothing.
+func HiddenSockets() ([]Port, error) {
+	var sockets []procSocket
+	for _, table := range procNetTables {
+		found, err := readProcNet(filepath.Join(procRoot, "net", table.file), table.protocol)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		sockets = append(sockets, found...)
+	}
+	if len(sockets) == 0 {
+		return nil, nil
+	}
+
+	owned := socketInodes()
+	users := make(map[string]string)
+	seen := make(map[string]struct{})
+	var hidden []Port
+	for _, socket := range sockets {
+		if _, ok := owned[socket.inode]; ok {
+			continue
+		}
+		key := fmt.Sprintf("%s|%d|%s", socket.Protocol, socket.Port.Port, socket.Address)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		uid := socket.User
+		name, ok := users[uid]
+		if !ok {
+			name = uid
+			if u, err := user.LookupId(uid); err == nil {
+				name = u.Username
+			}
+			users[uid] = name
+		}
+		socket.User = name
+		hidden = append(hidden, socket.Port)
+	}
+	return hidden, nil
+}
+
+// readProcNet parses a /proc/net/{tcp,udp}[6] table. TCP sockets are kept
+// only while listening and UDP sockets once bound, matching what lsof lists.
+// User holds the numeric UID.
+func readProcNet(path, protocol string) ([]procSocket, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sockets []procSocket
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		local, state, uid, inode := fields[1], fields[3], fields[7], fields[9]
+		if inode == "0" || (protocol == "tcp" && state != tcpListen) {
+			continue
+		}
+		address, port, err := parseProcAddress(local)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		if port == 0 {
+			continue
+		}
+		entry := Port{Process: HiddenOwner, User: uid, Protocol: protocol, Port: port, Address: address, Hidden: true}
+		if protocol == "tcp" {
+			entry.State = "LISTEN"
+		}
+		sockets = append(sockets, procSocket{Port: entry, inode: inode})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return sockets, nil
+}
+
+// parseProcAddress decodes "0100007F:0BB8" into 127.0.0.1 and 3000. The
+// kernel prints the address as native-endian 32-bit words; wildcards become
+// "*" as lsof prints them.
+func parseProcAddress(value string) (string, int, error) {
+	hexAddr, hexPort, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("bad address %q", value)
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("bad port in %q", value)
+	}
+	raw, err := hex.DecodeString(hexAddr)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("bad address %q", value)
+	}
+	for i := 0; i < len(raw); i += 4 {
+		binary.NativeEndian.PutUint32(raw[i:], binary.BigEndian.Uint32(raw[i:]))
+	}
+	ip := net.IP(raw)
+	if ip.IsUnspecified() {
+		return "*", int(port), nil
+	}
+	return ip.String(), int(port), nil
+}
+
+// socketInodes collects the socket inodes held by every process whose file
+// descriptors are readable. Processes of other users fail silently, which
+// is what leaves their sockets unmapped.
+func socketInodes() map[string]struct{} {
+	inodes := make(map[string]struct{})
+	fds, _ := filepath.Glob(filepath.Join(procRoot, "[0-9]*", "fd", "*"))
+	for _, fd := range fds {
+		target, err := os.Readlink(fd)
+		if err != nil {
+			continue
+		}
+		if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+			inodes[strings.TrimSuffix(inode, "]")] = struct{}{}
+		}
+	}
+	return inodes
+}
+
+// HiddenProvider wraps a Provider and appends the sockets it could not see
+// as Hidden rows.
+type HiddenProvider struct {
+	Provider
+}
+
+// List returns the wrapped provider's ports followed by hidden sockets.
+// Root sees every owner, so the scan is skipped; failing to read /proc only
+// loses the hidden rows.
+func (p HiddenProvider) List(ctx context.Context) ([]Port, error) {
+	entries, err := p.Provider.List(ctx)
+	if err != nil || os.Geteuid() == 0 {
+		return entries, err
+	}
+	hidden, err := HiddenSockets()
+	if err != nil {
+		return entries, nil
+	}
+	return append(entries, hidden...), nil
+}
+
+// HiddenCount reports how many entries are hidden-owner rows.
+func HiddenCount(entries []Port) int {
+	count := 0
+	for _, entry := range entries {
+		if entry.Hidden {
+			count++
+		}
+	}
+	return count
+}
+
+var _ Provider = HiddenProvider{}
@@ -0,0 +1,91 @@
+package ports
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const procNetHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+func TestHiddenSocketsReportsUnmappedInodes(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "net"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tables := map[string]string{
+		// 127.0.0.1:3000 is ours; *:8080 and a second socket on it are not;
+		// the established connection and TIME_WAIT entry are skipped.
+		"tcp": procNetHeader +
+			"   0: 0100007F:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 111 1 0 100 0 0 10 0\n" +
+			"   1: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 222 1 0 100 0 0 10 0\n" +
+			"   2: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 223 1 0 100 0 0 10 0\n" +
+			"   3: 0100007F:0BB8 0100007F:D431 01 00000000:00000000 00:00000000 00000000  1000        0 333 1 0 20 4 30 10 -1\n" +
+			"   4: 0100007F:1F91 0100007F:D432 06 00000000:00000000 03:00000D2A 00000000     0        0 0 3\n",
+		"tcp6": procNetHeader +
+			"   0: 00000000000000000000000001000000:1538 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000 54321        0 444 1 0 100 0 0 10 0\n",
+		"udp": procNetHeader +
+			"   0: 00000000:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 555 2 0 0\n",
+	}
+	for name, contents := range tables {
+		if err := os.WriteFile(filepath.Join(root, "net", name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fd := filepath.Join(root, "4242", "fd")
+	if err := os.MkdirAll(fd, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, target := range map[string]string{"3": "socket:[111]", "4": "/dev/null", "5": "socket:[333]"} {
+		if err := os.Symlink(target, filepath.Join(fd, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previous := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = previous })
+
+	hidden, err := HiddenSockets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Port{
+		{Process: HiddenOwner, User: "root", Protocol: "tcp", Port: 8080, Address: "*", State: "LISTEN", Hidden: true},
+		{Process: HiddenOwner, User: "54321", Protocol: "tcp", Port: 5432, Address: "::1", State: "LISTEN", Hidden: true},
+		{Process: HiddenOwner, User: "root", Protocol: "udp", Port: 53, Address: "*", Hidden: true},
+	}
+	if len(hidden) != len(want) {
+		t.Fatalf("HiddenSockets() = %+v, want %+v", hidden, want)
+	}
+	for i := range want {
+		if hidden[i] != want[i] {
+			t.Errorf("hidden[%d] = %+v, want %+v", i, hidden[i], want[i])
+		}
+	}
+	if got := HiddenCount(append(hidden, Port{PID: 1})); got != 3 {
+		t.Errorf("HiddenCount() = %d, want 3", got)
+	}
+}
+
+func TestParseProcAddress(t *testing.T) {
+	cases := []struct {
+		value   string
+		address string
+		port    int
+	}{
+		{"0100007F:0BB8", "127.0.0.1", 3000},
+		{"00000000:0050", "*", 80},
+		{"00000000000000000000000000000000:1F90", "*", 8080},
+		{"0000000000000000FFFF00000100007F:0016", "127.0.0.1", 22},
+	}
+	for _, tc := range cases {
+		address, port, err := parseProcAddress(tc.value)
+		if err != nil || address != tc.address || port != tc.port {
+			t.Errorf("parseProcAddress(%q) = %q, %d, %v; want %q, %d", tc.value, address, port, err, tc.address, tc.port)
+		}
+	}
+	if _, _, err := parseProcAddress("nonsense"); err == nil {
+		t.Error("parseProcAddress(nonsense) succeeded")
+	}
+}
@@ -0,0 +1,70 @@
+package ports
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// KillPolicy describes how Terminate escalates against a process.
+type KillPolicy struct {
+	// Signal is the first signal sent, named without the SIG prefix.
+	Signal string
+	// Grace is how long to wait for the process to exit after Signal.
+	Grace time.Duration
+	// Escalate sends SIGKILL when the process outlives Grace.
+	Escalate bool
+	// KillWait is how long to wait for the process to exit after SIGKILL.
+	KillWait time.Duration
+}
+
+// DefaultKillPolicy sends SIGTERM, waits half a second, then escalates to SIGKILL.
+func DefaultKillPolicy() KillPolicy {
+	return KillPolicy{
+		Signal:   "TERM",
+		Grace:    500 * time.Millisecond,
+		Escalate: true,
+		KillWait: 200 * time.Millisecond,
+	}
+}
+
+var supportedSignals = []string{"TERM", "INT", "HUP", "QUIT", "KILL", "USR1", "USR2"}
+
+// Signals lists the signal names ParseSignal accepts.
+func Signals() []string {
+	return append([]string(nil), supportedSignals...)
+}
+
+// ParseSignal normalises a signal name such as "sigterm" or "TERM" and reports
+// whether pzapp knows how to send it.
+func ParseSignal(name string) (string, error) {
+	normalized := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+	for _, known := range supportedSignals {
+		if normalized == known {
+			return normalized, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported signal %q (want one of %s)", name, strings.Join(supportedSignals, ", "))
+}
+
+// PermissionError is returned by Terminate when the caller may not signal
+// the process, typically because another user or root owns it. It matches
+// os.ErrPermission with errors.Is.
+type PermissionError struct {
+	PID    int
+	Signal string
+	Err    error
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("not permitted to send SIG%s to PID %d: %v", e.Signal, e.PID, e.Err)
+}
+
+func (e *PermissionError) Unwrap() error {
+	return e.Err
+}
+
+// Terminate kills a process using the default policy.
+func Terminate(pid int) error {
+	return TerminateWith(pid, DefaultKillPolicy())
+}
@@ -4,7 +4,7 @@ package ports
 
 import "fmt"
 
-// Terminate is not implemented on non-Unix systems yet.
-func Terminate(pid int) error {
+// TerminateWith is not implemented on non-Unix systems yet.
+func TerminateWith(pid int, policy KillPolicy) error {
     return fmt.Errorf("process termination is not supported on this platform")
 }
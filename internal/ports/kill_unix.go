@@ -8,15 +8,35 @@ import (
 	"time"
 )
 
-// Terminate attempts to gracefully kill a process, escalating from SIGTERM to SIGKILL if necessary.
-func Terminate(pid int) error {
-	// First, try SIGTERM (graceful termination)
-	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
-		return fmt.Errorf("failed to send SIGTERM to PID %d: %w", pid, err)
+var unixSignals = map[string]syscall.Signal{
+	"TERM": syscall.SIGTERM,
+	"INT":  syscall.SIGINT,
+	"HUP":  syscall.SIGHUP,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+}
+
+// TerminateWith attempts to gracefully kill a process, escalating to SIGKILL
+// if the policy allows it.
+func TerminateWith(pid int, policy KillPolicy) error {
+	name, err := ParseSignal(policy.Signal)
+	if err != nil {
+		return err
+	}
+	sig := unixSignals[name]
+
+	// First, send the configured signal (SIGTERM by default)
+	if err := syscall.Kill(pid, sig); err != nil {
+		if err == syscall.EPERM {
+			return &PermissionError{PID: pid, Signal: name, Err: err}
+		}
+		return fmt.Errorf("failed to send SIG%s to PID %d: %w", name, pid, err)
 	}
 
 	// Wait a moment to see if process terminates gracefully
-	time.Sleep(500 * time.Millisecond)
+	time.Sleep(policy.Grace)
 
 	// Check if process still exists
 	if err := syscall.Kill(pid, 0); err != nil {
@@ -24,16 +44,23 @@ func Terminate(pid int) error {
 		return nil // Success!
 	}
 
+	if !policy.Escalate || sig == syscall.SIGKILL {
+		return fmt.Errorf("process %d is still running after SIG%s", pid, name)
+	}
+
 	// Process is still alive, escalate to SIGKILL (forceful termination)
 	if err := syscall.Kill(pid, syscall.SIGKILL); err != nil {
+		if err == syscall.EPERM {
+			return &PermissionError{PID: pid, Signal: "KILL", Err: err}
+		}
 		return fmt.Errorf("failed to send SIGKILL to PID %d: %w", pid, err)
 	}
 
 	// Wait a bit more and check again
-	time.Sleep(200 * time.Millisecond)
+	time.Sleep(policy.KillWait)
 	if err := syscall.Kill(pid, 0); err != nil {
 		return nil // Process finally died
 	}
 
-	return fmt.Errorf("process %d survived both SIGTERM and SIGKILL - it's unstoppable! 💀", pid)
+	return fmt.Errorf("process %d survived both SIG%s and SIGKILL - it's unstoppable! 💀", pid, name)
 }
@@ -0,0 +1,24 @@
+//go:build unix
+
+package ports
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestTerminateReportsPermissionError(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root may signal any process")
+	}
+
+	err := TerminateWith(1, KillPolicy{Signal: "TERM"})
+	var permErr *PermissionError
+	if !errors.As(err, &permErr) {
+		t.Fatalf("TerminateWith(1) = %v, want a *PermissionError", err)
+	}
+	if permErr.PID != 1 || permErr.Signal != "TERM" || !errors.Is(err, os.ErrPermission) {
+		t.Fatalf("PermissionError = %+v", permErr)
+	}
+}
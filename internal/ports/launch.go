@@ -0,0 +1,77 @@
+package ports
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Launch starts snap.Argv in snap.Cwd, detached from pzapp so it outlives the
+// TUI. snap.Env is layered over pzapp's own environment, and a bare command
+// name is looked up in its PATH. Output is appended to logPath, which only
+// the user can read, when it is set and discarded otherwise. It returns the
+// new PID.
+func Launch(snap ProcessSnapshot, logPath string) (int, error) {
+	if len(snap.Argv) == 0 {
+		return 0, fmt.Errorf("launch: no command recorded")
+	}
+
+	cmd := exec.Command(snapshotCommand(snap), snap.Argv[1:]...)
+	cmd.Args[0] = snap.Argv[0]
+	cmd.Dir = snap.Cwd
+	cmd.Env = os.Environ()
+	for name, value := range snap.Env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	detach(cmd)
+
+	if logPath != "" {
+		if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+			return 0, fmt.Errorf("launch: create log directory: %w", err)
+		}
+		log, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return 0, fmt.Errorf("launch: open log: %w", err)
+		}
+		defer log.Close()
+		cmd.Stdout = log
+		cmd.Stderr = log
+	}
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("launch %s: %w", snap.Argv[0], err)
+	}
+	// Reap the child if it exits while pzapp is still running.
+	go cmd.Wait()
+	return cmd.Process.Pid, nil
+}
+
+// snapshotCommand resolves the command of snap the way its shell did: a
+// bare name is searched in the recorded PATH, with relative entries taken
+// from the recorded working directory, so commands from nvm, venv or asdf
+// shells are found even though pzapp's own PATH lacks them. Names the
+// recorded PATH does not have are left to pzapp's.
+func snapshotCommand(snap ProcessSnapshot) string {
+	name := snap.Argv[0]
+	path, ok := snap.Env["PATH"]
+	if !ok || strings.ContainsRune(name, filepath.Separator) {
+		return name
+	}
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			dir = "."
+		}
+		if !filepath.IsAbs(dir) {
+			if snap.Cwd == "" {
+				continue
+			}
+			dir = filepath.Join(snap.Cwd, dir)
+		}
+		if found, err := exec.LookPath(filepath.Join(dir, name)); err == nil {
+			return found
+		}
+	}
+	return name
+}
@@ -11,6 +11,10 @@ import (
 	"strings"
 )
 
+// lsofArgs select listening TCP and all UDP sockets, in lsof's field
+// output format.
+var lsofArgs = []string{"-nP", "-iTCP", "-sTCP:LISTEN", "-iUDP", "-FpcfLnuPT"}
+
 // LsofProvider shells out to lsof to discover active ports.
 type LsofProvider struct {
 	// Path to the lsof executable. Defaults to "lsof" when empty.
@@ -30,21 +34,35 @@ func (p *LsofProvider) List(ctx context.Context) ([]Port, error) {
 		path = "lsof"
 	}
 
-	args := []string{"-nP", "-iTCP", "-sTCP:LISTEN", "-iUDP", "-FpcfLnuPT"}
-	cmd := exec.CommandContext(ctx, path, args...)
+	cmd := exec.CommandContext(ctx, path, lsofArgs...)
 	output, err := cmd.Output()
 	if err != nil {
-		if ee := (&exec.ExitError{}); errors.As(err, &ee) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		ee := (*exec.ExitError)(nil)
+		if !errors.As(err, &ee) {
+			return nil, fmt.Errorf("executing %s: %w", path, err)
+		}
+		// lsof also exits 1 when one of the selections, such as -iUDP,
+		// matched nothing; whatever the others found is still valid. Any
+		// other exit, or 1 with nothing but an error message, is a failure.
+		if ee.ExitCode() != 1 || (len(output) == 0 && len(ee.Stderr) > 0) {
 			return nil, fmt.Errorf("lsof failed: %w", err)
 		}
-		return nil, fmt.Errorf("executing %s: %w", path, err)
 	}
 
 	entries, err := parseLsofOutput(string(output))
 	if err != nil {
 		return nil, err
 	}
+	enrichProcesses(ctx, entries)
+	sortEntries(entries)
+	return entries, nil
+}
 
+// sortEntries orders entries by port, protocol, PID and address.
+func sortEntries(entries []Port) {
 	sort.Slice(entries, func(i, j int) bool {
 		if entries[i].Port == entries[j].Port {
 			if entries[i].Protocol == entries[j].Protocol {
@@ -57,8 +75,6 @@ func (p *LsofProvider) List(ctx context.Context) ([]Port, error) {
 		}
 		return entries[i].Port < entries[j].Port
 	})
-
-	return entries, nil
 }
 
 func parseLsofOutput(out string) ([]Port, error) {
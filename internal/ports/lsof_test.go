@@ -1,7 +1,11 @@
 package ports
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -69,6 +73,52 @@ n*:68
 	}
 }
 
+func TestLsofProviderExitStatus(t *testing.T) {
+	const listener = "printf 'p1234\\ncnode\\nLnaveed\\nf11\\nPTCP\\nn*:3000\\nTST=LISTEN\\n'\n"
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		name   string
+		script string
+		ctx    context.Context
+		// ports is what List returns; err is part of its error.
+		ports []int
+		err   string
+	}{
+		// lsof exits 1 when -iUDP matches nothing, after printing the TCP sockets.
+		{"partial match", listener + "exit 1\n", context.Background(), []int{3000}, ""},
+		{"nothing matched", "exit 1\n", context.Background(), nil, ""},
+		{"only an error", "echo 'lsof: unsupported option' >&2\nexit 1\n", context.Background(), nil, "lsof failed"},
+		{"other exit status", listener + "exit 2\n", context.Background(), nil, "lsof failed"},
+		{"killed", listener + "kill -9 $$\n", context.Background(), nil, "lsof failed"},
+		{"context ended", listener, expired, nil, context.Canceled.Error()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := filepath.Join(t.TempDir(), "lsof")
+			if err := os.WriteFile(fake, []byte("#!/bin/sh\n"+tc.script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := (&LsofProvider{Path: fake}).List(tc.ctx)
+			switch {
+			case tc.err == "" && err != nil:
+				t.Fatalf("List() = %v", err)
+			case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+				t.Fatalf("List() error = %v, want %q", err, tc.err)
+			}
+			var got []int
+			for _, entry := range entries {
+				got = append(got, entry.Port)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.ports) {
+				t.Errorf("List() ports = %v, want %v", got, tc.ports)
+			}
+		})
+	}
+}
+
 func keyFor(pid, port int) string {
 	return fmt.Sprintf("%d/%d", pid, port)
 }
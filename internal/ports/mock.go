@@ -15,12 +15,18 @@ func NewMockProvider() Provider {
 
 // List returns a fixed slice of synthetic port entries.
 func (MockProvider) List(ctx context.Context) ([]Port, error) {
+	now := time.Now().Truncate(time.Second)
 	sample := []Port{
-		{PID: 4521, Process: "node", User: "naveed", Protocol: "tcp", Port: 3000, Address: "0.0.0.0", State: "LISTEN"},
-		{PID: 9112, Process: "postgres", User: "postgres", Protocol: "tcp", Port: 5432, Address: "127.0.0.1", State: "LISTEN"},
-		{PID: 2048, Process: "redis-server", User: "redis", Protocol: "tcp", Port: 6379, Address: "127.0.0.1", State: "LISTEN"},
-		{PID: 7320, Process: "python", User: "naveed", Protocol: "tcp", Port: 8000, Address: "127.0.0.1", State: "LISTEN"},
-		{PID: 8871, Process: "nginx", User: "root", Protocol: "tcp", Port: 443, Address: "0.0.0.0", State: "LISTEN"},
+		{PID: 4521, Process: "node", User: "naveed", Protocol: "tcp", Port: 3000, Address: "0.0.0.0", State: "LISTEN",
+			Cmdline: "node node_modules/.bin/next dev", StartTime: now.Add(-42 * time.Minute)},
+		{PID: 9112, Process: "postgres", User: "postgres", Protocol: "tcp", Port: 5432, Address: "127.0.0.1", State: "LISTEN",
+			Cmdline: "postgres -D /var/lib/postgresql/data", StartTime: now.Add(-3 * 24 * time.Hour), Unit: "postgresql.service"},
+		{PID: 2048, Process: "redis-server", User: "redis", Protocol: "tcp", Port: 6379, Address: "127.0.0.1", State: "LISTEN",
+			Cmdline: "redis-server 127.0.0.1:6379", StartTime: now.Add(-26 * time.Hour), Unit: "redis-server.service"},
+		{PID: 7320, Process: "python", User: "naveed", Protocol: "tcp", Port: 8000, Address: "127.0.0.1", State: "LISTEN",
+			Cmdline: "python -m http.server 8000", StartTime: now.Add(-5 * time.Minute)},
+		{PID: 8871, Process: "nginx", User: "root", Protocol: "tcp", Port: 443, Address: "0.0.0.0", State: "LISTEN",
+			Cmdline: "nginx: master process /usr/sbin/nginx", StartTime: now.Add(-7 * 24 * time.Hour), Unit: "nginx.service"},
 	}
 
 	// Simulate a tiny delay to exercise the loading state.
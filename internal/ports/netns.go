@@ -0,0 +1,285 @@
+package ports
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// netnsDir holds the namespaces named with `ip netns add`, as bind mounts.
+var netnsDir = "/run/netns"
+
+// containerRuntimes maps cgroup name prefixes of container scopes to the
+// runtime that created them.
+var containerRuntimes = []struct {
+	prefix  string
+	runtime string
+}{
+	{"docker-", "docker"},
+	{"libpod-", "podman"},
+	{"cri-containerd-", "containerd"},
+	{"crio-", "cri-o"},
+}
+
+// netNamespace is a network namespace and the processes inside it, lowest
+// PID first.
+type netNamespace struct {
+	id   string
+	pids []int
+}
+
+// NamespaceSockets returns the listening sockets of network namespaces
+// other than pzapp's own: containers, `ip netns` namespaces and the like.
+// lsof only decodes sockets of its own namespace, so unless a port is
+// published these listeners are otherwise invisible. Each row is tagged in
+// Container with the namespace's name, or the runtime and ID of the
+// container owning it. Only processes this user may inspect are seen, so
+// without root that is limited to their own (rootless) containers; sockets
+// whose owner is not readable are returned as Hidden rows. The rows carry
+// their namespace in NetNS, as their addresses cannot be reached from here.
+// Systems without /proc yield nothing.
+func NamespaceSockets(ctx context.Context) ([]Port, error) {
+	own, err := os.Readlink(filepath.Join(procRoot, "self", "ns", "net"))
+	if err != nil {
+		return nil, nil
+	}
+
+	named := namedNamespaces()
+	users := make(map[string]string)
+	var found []Port
+	for _, ns := range foreignNamespaces(own) {
+		label := namespaceLabel(ns, named)
+		owners := namespaceSocketOwners(ns)
+		dir := filepath.Join(procRoot, strconv.Itoa(ns.pids[0]), "net")
+		seen := make(map[string]struct{})
+		for _, table := range procNetTables {
+			sockets, err := readProcNet(filepath.Join(dir, table.file), table.protocol)
+			if err != nil {
+				continue
+			}
+			for _, socket := range sockets {
+				entry := socket.Port
+				entry.Container, entry.NetNS = label, ns.id
+				if pid, ok := owners[socket.inode]; ok {
+					entry.PID, entry.Hidden = pid, false
+					entry.Process = processName(pid)
+				}
+				key := fmt.Sprintf("%s|%d|%s|%d", entry.Protocol, entry.Port, entry.Address, entry.PID)
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+
+				uid := entry.User
+				name, ok := users[uid]
+				if !ok {
+					name = uid
+					if u, err := user.LookupId(uid); err == nil {
+						name = u.Username
+					}
+					users[uid] = name
+				}
+				entry.User = name
+				found = append(found, entry)
+			}
+		}
+	}
+	enrichProcesses(ctx, found)
+	return found, nil
+}
+
+// foreignNamespaces groups the inspectable processes by network namespace,
+// leaving out own, in order of their lowest PID.
+func foreignNamespaces(own string) []*netNamespace {
+	dirs, _ := filepath.Glob(filepath.Join(procRoot, "[0-9]*"))
+	byID := make(map[string]*netNamespace)
+	var namespaces []*netNamespace
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(filepath.Base(dir))
+		if err != nil {
+			continue
+		}
+		id, err := os.Readlink(filepath.Join(dir, "ns", "net"))
+		if err != nil || id == own {
+			continue
+		}
+		ns, ok := byID[id]
+		if !ok {
+			ns = &netNamespace{id: id}
+			byID[id] = ns
+			namespaces = append(namespaces, ns)
+		}
+		ns.pids = append(ns.pids, pid)
+	}
+	for _, ns := range namespaces {
+		sort.Ints(ns.pids)
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].pids[0] < namespaces[j].pids[0] })
+	return namespaces
+}
+
+// namespaceSocketOwners maps the socket inodes held by the processes of ns
+// to the lowest PID holding each.
+func namespaceSocketOwners(ns *netNamespace) map[string]int {
+	owners := make(map[string]int)
+	for _, pid := range ns.pids {
+		fds, _ := filepath.Glob(filepath.Join(procRoot, strconv.Itoa(pid), "fd", "*"))
+		for _, fd := range fds {
+			target, err := os.Readlink(fd)
+			if err != nil {
+				continue
+			}
+			inode, ok := strings.CutPrefix(target, "socket:[")
+			if !ok {
+				continue
+			}
+			inode = strings.TrimSuffix(inode, "]")
+			if _, taken := owners[inode]; !taken {
+				owners[inode] = pid
+			}
+		}
+	}
+	return owners
+}
+
+// namedNamespaces stats the namespaces in netnsDir, by name.
+func namedNamespaces() map[string]os.FileInfo {
+	entries, err := os.ReadDir(netnsDir)
+	if err != nil {
+		return nil
+	}
+	named := make(map[string]os.FileInfo, len(entries))
+	for _, entry := range entries {
+		if info, err := os.Stat(filepath.Join(netnsDir, entry.Name())); err == nil {
+			named[entry.Name()] = info
+		}
+	}
+	return named
+}
+
+// namespaceLabel names ns for the container column: "netns:NAME" for
+// `ip netns` namespaces, "RUNTIME:ID" when a container owns it and
+// "netns:INODE" otherwise.
+func namespaceLabel(ns *netNamespace, named map[string]os.FileInfo) string {
+	pid := strconv.Itoa(ns.pids[0])
+	if info, err := os.Stat(filepath.Join(procRoot, pid, "ns", "net")); err == nil {
+		names := make([]string, 0, len(named))
+		for name, mount := range named {
+			if os.SameFile(info, mount) {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			return "netns:" + names[0]
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(procRoot, pid, "cgroup")); err == nil {
+		if container := ParseCgroupContainer(string(data)); container != "" {
+			return container
+		}
+	}
+	inode := strings.TrimSuffix(strings.TrimPrefix(ns.id, "net:["), "]")
+	return "netns:" + inode
+}
+
+// ParseCgroupContainer finds the container owning a process from the
+// contents of /proc/<pid>/cgroup, as "RUNTIME:ID" with the ID shortened to
+// 12 characters as `docker ps` prints it, or "lxc:NAME". It recognises the
+// systemd scopes of docker, podman, containerd and CRI-O as well as the
+// cgroupfs layouts of docker (also nested, as in Docker-in-Docker),
+// Kubernetes and LXC. Other processes yield "".
+func ParseCgroupContainer(data string) string {
+	for _, line := range strings.Split(data, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		segments := strings.Split(parts[2], "/")
+		for i := len(segments) - 1; i >= 0; i-- {
+			segment := strings.TrimSuffix(segments[i], ".scope")
+			if name, ok := strings.CutPrefix(segment, "lxc.payload."); ok {
+				return "lxc:" + name
+			}
+			if i > 0 && segments[i-1] == "lxc" {
+				return "lxc:" + segment
+			}
+			for _, r := range containerRuntimes {
+				if id, ok := strings.CutPrefix(segment, r.prefix); ok && isContainerID(id) {
+					return r.runtime + ":" + id[:12]
+				}
+			}
+			if !isContainerID(segment) {
+				continue
+			}
+			for _, parent := range segments[:i] {
+				switch {
+				case parent == "docker":
+					return "docker:" + segment[:12]
+				case strings.HasPrefix(parent, "kubepods"):
+					return "k8s:" + segment[:12]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// isContainerID reports whether s is a 64-digit hex container ID.
+func isContainerID(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// processName reads the command name of pid.
+func processName(pid int) string {
+	data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return fmt.Sprintf("PID %d", pid)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// NamespaceProvider wraps a Provider and appends the listeners found in
+// other network namespaces.
+type NamespaceProvider struct {
+	Provider
+}
+
+// List returns the wrapped provider's ports followed by those of other
+// namespaces. Failing to read /proc only loses the namespace rows.
+func (p NamespaceProvider) List(ctx context.Context) ([]Port, error) {
+	entries, err := p.Provider.List(ctx)
+	if err != nil {
+		return entries, err
+	}
+	found, err := NamespaceSockets(ctx)
+	if err != nil {
+		return entries, nil
+	}
+	listed := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		listed[fmt.Sprintf("%s|%d|%d", strings.ToLower(entry.Protocol), entry.Port, entry.PID)] = struct{}{}
+	}
+	for _, entry := range found {
+		if _, ok := listed[fmt.Sprintf("%s|%d|%d", entry.Protocol, entry.Port, entry.PID)]; ok && !entry.Hidden {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+var _ Provider = NamespaceProvider{}
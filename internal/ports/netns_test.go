@@ -0,0 +1,118 @@
+package ports
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// procFixture builds a fake /proc from files relative to its root; values
+// starting with "->" become symlinks.
+func procFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		var err error
+		if target, ok := strings.CutPrefix(contents, "->"); ok {
+			err = os.Symlink(strings.ReplaceAll(target, "$ROOT", root), path)
+		} else {
+			err = os.WriteFile(path, []byte(contents), 0o644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestNamespaceSocketsTagsContainersAndNamespaces(t *testing.T) {
+	const dockerID = "3f2a1b9c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8"
+	root := procFixture(t, map[string]string{
+		"self/ns/net": "->net:[4026531840]",
+		// PID 100 shares pzapp's namespace and is lsof's business.
+		"100/ns/net": "->net:[4026531840]",
+		"100/net/tcp": procNetHeader +
+			"   0: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 100 1 0 100 0 0 10 0\n",
+		// PIDs 200 and 201 are a docker container; nginx (201) holds *:80,
+		// nobody readable holds 127.0.0.1:9000.
+		"200/ns/net": "->net:[4026532451]",
+		"200/cgroup": "0::/system.slice/docker-" + dockerID + ".scope\n",
+		"201/ns/net": "->net:[4026532451]",
+		"201/comm":   "nginx\n",
+		"201/fd/3":   "->socket:[900]",
+		"201/fd/4":   "->/dev/null",
+		"200/net/tcp": procNetHeader +
+			"   0: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 900 1 0 100 0 0 10 0\n" +
+			"   1: 0100007F:2328 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 901 1 0 100 0 0 10 0\n" +
+			"   2: 0200000A:0050 0100000A:D431 01 00000000:00000000 00:00000000 00000000     0        0 902 1 0 20 4 30 10 -1\n",
+		// PID 300 runs in the namespace `ip netns add blue` created.
+		"300/ns/net": "->$ROOT/run/netns/blue",
+		"300/comm":   "dnsmasq\n",
+		"300/fd/5":   "->socket:[950]",
+		"300/net/udp": procNetHeader +
+			"   0: 00000000:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 950 2 0 0\n",
+		"run/netns/blue": "",
+	})
+
+	previousProc, previousNetns := procRoot, netnsDir
+	procRoot, netnsDir = root, filepath.Join(root, "run", "netns")
+	t.Cleanup(func() { procRoot, netnsDir = previousProc, previousNetns })
+
+	found, err := NamespaceSockets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Port{
+		{PID: 201, Process: "nginx", User: "root", Protocol: "tcp", Port: 80, Address: "*", State: "LISTEN", Container: "docker:3f2a1b9c4d5e", NetNS: "net:[4026532451]"},
+		{Process: HiddenOwner, User: "root", Protocol: "tcp", Port: 9000, Address: "127.0.0.1", State: "LISTEN", Hidden: true, Container: "docker:3f2a1b9c4d5e", NetNS: "net:[4026532451]"},
+		{PID: 300, Process: "dnsmasq", User: "root", Protocol: "udp", Port: 53, Address: "*", Container: "netns:blue", NetNS: filepath.Join(root, "run", "netns", "blue")},
+	}
+	if len(found) != len(want) {
+		t.Fatalf("NamespaceSockets() = %+v, want %+v", found, want)
+	}
+	for i := range want {
+		got := found[i]
+		got.Cmdline, got.StartTime = "", want[i].StartTime // filled in by ps when the PID happens to exist
+		if got != want[i] {
+			t.Errorf("found[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestNamespaceSocketsWithoutProc(t *testing.T) {
+	previous := procRoot
+	procRoot = filepath.Join(t.TempDir(), "absent")
+	t.Cleanup(func() { procRoot = previous })
+
+	found, err := NamespaceSockets(context.Background())
+	if err != nil || found != nil {
+		t.Fatalf("NamespaceSockets() = %v, %v; want nothing", found, err)
+	}
+}
+
+func TestParseCgroupContainer(t *testing.T) {
+	const id = "3f2a1b9c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8"
+	const inner = "aaaabbbbccccddddeeeeffff00001111222233334444555566667777888899990"
+	cases := map[string]string{
+		"0::/system.slice/docker-" + id + ".scope\n":                                            "docker:3f2a1b9c4d5e",
+		"12:memory:/docker/" + id + "\n1:name=systemd:/docker/" + id + "\n":                     "docker:3f2a1b9c4d5e",
+		"0::/docker/" + id + "/docker/" + inner[:64] + "\n":                                     "docker:aaaabbbbcccc",
+		"0::/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-" + id + ".scope\n": "podman:3f2a1b9c4d5e",
+		"0::/kubepods.slice/kubepods-burstable.slice/cri-containerd-" + id + ".scope\n":         "containerd:3f2a1b9c4d5e",
+		"0::/kubepods/burstable/pod1234/" + id + "\n":                                           "k8s:3f2a1b9c4d5e",
+		"0::/lxc.payload.web01/system.slice\n":                                                  "lxc:web01",
+		"0::/system.slice/nginx.service\n":                                                      "",
+		"0::/user.slice/user-1000.slice/session-2.scope\n":                                      "",
+	}
+	for data, want := range cases {
+		if got := ParseCgroupContainer(data); got != want {
+			t.Errorf("ParseCgroupContainer(%q) = %q, want %q", data, got, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ports
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type processInfo struct {
+	cmdline string
+	started time.Time
+}
+
+// enrichProcesses fills in command lines and start times using ps, which
+// reports both on Linux and macOS. Failures leave the fields empty rather than
+// failing the whole listing.
+func enrichProcesses(ctx context.Context, entries []Port) {
+	if len(entries) == 0 {
+		return
+	}
+
+	seen := make(map[int]struct{})
+	pids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if _, ok := seen[entry.PID]; ok || entry.PID <= 0 {
+			continue
+		}
+		seen[entry.PID] = struct{}{}
+		pids = append(pids, strconv.Itoa(entry.PID))
+	}
+	if len(pids) == 0 {
+		return
+	}
+
+	cmd := exec.CommandContext(ctx, "ps", "-o", "pid=,etime=,command=", "-p", strings.Join(pids, ","))
+	output, err := cmd.Output()
+	if err != nil && len(output) == 0 {
+		return
+	}
+
+	info := parsePsOutput(string(output), time.Now())
+	for i := range entries {
+		if proc, ok := info[entries[i].PID]; ok {
+			entries[i].Cmdline = proc.cmdline
+			entries[i].StartTime = proc.started
+		}
+	}
+}
+
+// parsePsOutput reads `ps -o pid=,etime=,command=` lines.
+func parsePsOutput(out string, now time.Time) map[int]processInfo {
+	info := make(map[int]processInfo)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		proc := processInfo{}
+		if elapsed, err := parseElapsed(fields[1]); err == nil {
+			proc.started = now.Add(-elapsed).Truncate(time.Second)
+		}
+		if len(fields) > 2 {
+			proc.cmdline = strings.Join(fields[2:], " ")
+		}
+		info[pid] = proc
+	}
+	return info
+}
+
+// parseElapsed parses the ps etime format, [[dd-]hh:]mm:ss.
+func parseElapsed(value string) (time.Duration, error) {
+	var days int
+	if d, rest, ok := strings.Cut(value, "-"); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, fmt.Errorf("parse elapsed %q: %w", value, err)
+		}
+		days, value = n, rest
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("parse elapsed %q: unexpected format", value)
+	}
+
+	var total time.Duration
+	units := []time.Duration{time.Hour, time.Minute, time.Second}[3-len(parts):]
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("parse elapsed %q: %w", value, err)
+		}
+		total += time.Duration(n) * units[i]
+	}
+	return total + time.Duration(days)*24*time.Hour, nil
+}
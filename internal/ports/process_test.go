@@ -0,0 +1,54 @@
+package ports
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseElapsed(t *testing.T) {
+	cases := map[string]time.Duration{
+		"00:07":      7 * time.Second,
+		"12:34":      12*time.Minute + 34*time.Second,
+		"01:02:03":   time.Hour + 2*time.Minute + 3*time.Second,
+		"3-04:05:06": 3*24*time.Hour + 4*time.Hour + 5*time.Minute + 6*time.Second,
+	}
+	for input, want := range cases {
+		got, err := parseElapsed(input)
+		if err != nil {
+			t.Fatalf("parseElapsed(%q): %v", input, err)
+		}
+		if got != want {
+			t.Errorf("parseElapsed(%q) = %s, want %s", input, got, want)
+		}
+	}
+
+	for _, bad := range []string{"", "7", "a:b", "x-01:02"} {
+		if _, err := parseElapsed(bad); err == nil {
+			t.Errorf("parseElapsed(%q): expected error", bad)
+		}
+	}
+}
+
+func TestParsePsOutput(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	raw := `  4521    01:00:00 node /usr/local/bin/vite --port 3000
+  9112 2-00:00:00 postgres -D /var/lib/postgres
+garbage
+`
+
+	info := parsePsOutput(raw, now)
+	if len(info) != 2 {
+		t.Fatalf("expected 2 processes, got %d: %+v", len(info), info)
+	}
+
+	node := info[4521]
+	if node.cmdline != "node /usr/local/bin/vite --port 3000" {
+		t.Fatalf("cmdline = %q", node.cmdline)
+	}
+	if !node.started.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("started = %s", node.started)
+	}
+	if !info[9112].started.Equal(now.Add(-48 * time.Hour)) {
+		t.Fatalf("postgres started = %s", info[9112].started)
+	}
+}
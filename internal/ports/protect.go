@@ -0,0 +1,175 @@
+package ports
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Protection levels reported in Port.Protection.
+const (
+	// ProtectConfirm requires typing the process name before killing.
+	ProtectConfirm = "confirm"
+	// ProtectRefuse blocks the kill outright.
+	ProtectRefuse = "refuse"
+)
+
+// ProtectRule marks processes that must not be killed casually. Every field
+// that is set must match; Process, User and Exe are path.Match globs.
+type ProtectRule struct {
+	// Process matches the process name or the base name of its executable.
+	Process string
+	User    string
+	Port    int
+	// Exe matches the full executable path.
+	Exe string
+	// Action is ProtectConfirm or ProtectRefuse.
+	Action string
+}
+
+// DefaultProtectRules guards init systems, remote access, container runtimes
+// and VPN clients.
+func DefaultProtectRules() []ProtectRule {
+	return []ProtectRule{
+		{Process: "init", Action: ProtectRefuse},
+		{Process: "systemd", Action: ProtectRefuse},
+		{Process: "launchd", Action: ProtectRefuse},
+		{Process: "sshd", Action: ProtectConfirm},
+		{Process: "dockerd", Action: ProtectConfirm},
+		{Process: "containerd", Action: ProtectConfirm},
+		{Process: "com.docker.*", Action: ProtectConfirm},
+		{Process: "tailscaled", Action: ProtectConfirm},
+		{Process: "openvpn", Action: ProtectConfirm},
+		{Process: "wireguard*", Action: ProtectConfirm},
+	}
+}
+
+// Validate reports whether the rule can match anything and its globs parse.
+func (r ProtectRule) Validate() error {
+	if r.Process == "" && r.User == "" && r.Port == 0 && r.Exe == "" {
+		return fmt.Errorf("rule must set process, user, port or exe")
+	}
+	if r.Port < 0 || r.Port > 65535 {
+		return fmt.Errorf("invalid port %d", r.Port)
+	}
+	for _, pattern := range []string{r.Process, r.User, r.Exe} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad pattern %q", pattern)
+		}
+	}
+	switch r.Action {
+	case ProtectConfirm, ProtectRefuse:
+		return nil
+	}
+	return fmt.Errorf("action must be %q or %q", ProtectConfirm, ProtectRefuse)
+}
+
+// String describes the rule for the kill modal, e.g. "process sshd".
+func (r ProtectRule) String() string {
+	var parts []string
+	if r.Process != "" {
+		parts = append(parts, "process "+r.Process)
+	}
+	if r.User != "" {
+		parts = append(parts, "user "+r.User)
+	}
+	if r.Port != 0 {
+		parts = append(parts, "port "+strconv.Itoa(r.Port))
+	}
+	if r.Exe != "" {
+		parts = append(parts, "exe "+r.Exe)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// matches reports whether p satisfies every field of the rule. exe resolves
+// the executable path lazily, since it costs a syscall per process.
+func (r ProtectRule) matches(p Port, exe func() string) bool {
+	if r.Port != 0 && r.Port != p.Port {
+		return false
+	}
+	if r.User != "" && !globMatch(r.User, p.User) {
+		return false
+	}
+	if r.Process != "" && !globMatch(r.Process, p.Process) {
+		if path := exe(); path == "" || !globMatch(r.Process, filepath.Base(path)) {
+			return false
+		}
+	}
+	if r.Exe != "" && !globMatch(r.Exe, exe()) {
+		return false
+	}
+	return true
+}
+
+func globMatch(pattern, value string) bool {
+	matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+	return matched
+}
+
+// Protect sets Protection and ProtectedBy on entries matching rules. Refusing
+// rules win over confirming ones.
+func Protect(rules []ProtectRule, entries []Port) {
+	if len(rules) == 0 {
+		return
+	}
+	exes := make(map[int]string)
+	for i := range entries {
+		entry := &entries[i]
+		exe := func() string {
+			path, ok := exes[entry.PID]
+			if !ok {
+				path = ExePath(*entry)
+				exes[entry.PID] = path
+			}
+			return path
+		}
+		for _, rule := range rules {
+			if entry.Protection == ProtectRefuse {
+				break
+			}
+			if rule.Action == entry.Protection || !rule.matches(*entry, exe) {
+				continue
+			}
+			entry.Protection = rule.Action
+			entry.ProtectedBy = rule.String()
+		}
+	}
+}
+
+// ExePath returns the executable of p's process from /proc, or the first
+// word of its command line when that is an absolute path. Remote processes
+// only have their command line.
+func ExePath(p Port) string {
+	if p.PID > 0 && p.Host == "" {
+		if exe, err := os.Readlink(filepath.Join(procRoot, strconv.Itoa(p.PID), "exe")); err == nil {
+			return strings.TrimSuffix(exe, " (deleted)")
+		}
+	}
+	if fields := strings.Fields(p.Cmdline); len(fields) > 0 && filepath.IsAbs(fields[0]) {
+		return fields[0]
+	}
+	return ""
+}
+
+// ProtectProvider wraps a Provider and marks protected processes.
+type ProtectProvider struct {
+	Provider
+	Rules []ProtectRule
+}
+
+// List returns the wrapped provider's ports with Protection filled in.
+func (p ProtectProvider) List(ctx context.Context) ([]Port, error) {
+	entries, err := p.Provider.List(ctx)
+	if err != nil {
+		return entries, err
+	}
+	Protect(p.Rules, entries)
+	return entries, nil
+}
+
+var _ Provider = ProtectProvider{}
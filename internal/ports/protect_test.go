@@ -0,0 +1,90 @@
+package ports
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProtectMarksMatchingProcesses(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "77"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/opt/vpn/bin/corp-vpn-agent", filepath.Join(root, "77", "exe")); err != nil {
+		t.Fatal(err)
+	}
+	previous := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = previous })
+
+	rules := append(DefaultProtectRules(),
+		ProtectRule{User: "postgres", Port: 5432, Action: ProtectConfirm},
+		ProtectRule{Exe: "/opt/vpn/bin/*", Action: ProtectRefuse},
+		ProtectRule{Port: 22, Action: ProtectRefuse},
+	)
+	entries := []Port{
+		{PID: 1, Process: "systemd", Port: 5355},
+		{PID: 900, Process: "sshd", Port: 22},
+		{PID: 901, Process: "sshd", Port: 2222},
+		{PID: 2, Process: "Docker", Cmdline: "/usr/bin/dockerd -H fd://", Port: 2375},
+		{PID: 3, Process: "com.docker.backend", Port: 6443},
+		{PID: 4, Process: "postgres", User: "postgres", Port: 5432},
+		{PID: 5, Process: "postgres", User: "naveed", Port: 5432},
+		{PID: 77, Process: "agent", Port: 8443},
+		{PID: 4521, Process: "node", Port: 3000},
+	}
+	Protect(rules, entries)
+
+	want := []struct{ protection, by string }{
+		{ProtectRefuse, "process systemd"},
+		{ProtectRefuse, "port 22"},
+		{ProtectConfirm, "process sshd"},
+		{ProtectConfirm, "process dockerd"}, // matched via the executable name
+		{ProtectConfirm, "process com.docker.*"},
+		{ProtectConfirm, "user postgres, port 5432"},
+		{"", ""},
+		{ProtectRefuse, "exe /opt/vpn/bin/*"},
+		{"", ""},
+	}
+	for i, w := range want {
+		if entries[i].Protection != w.protection || entries[i].ProtectedBy != w.by {
+			t.Errorf("%s:%d = %q (%q), want %q (%q)", entries[i].Process, entries[i].Port,
+				entries[i].Protection, entries[i].ProtectedBy, w.protection, w.by)
+		}
+	}
+}
+
+func TestProtectRuleValidate(t *testing.T) {
+	bad := []ProtectRule{
+		{Action: ProtectConfirm},
+		{Process: "sshd", Action: "warn"},
+		{Process: "[", Action: ProtectRefuse},
+		{Port: 70000, Action: ProtectRefuse},
+	}
+	for _, rule := range bad {
+		if err := rule.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", rule)
+		}
+	}
+	for _, rule := range DefaultProtectRules() {
+		if err := rule.Validate(); err != nil {
+			t.Errorf("default rule %+v: %v", rule, err)
+		}
+	}
+}
+
+func TestProtectProviderAnnotatesList(t *testing.T) {
+	provider := ProtectProvider{
+		Provider: staticProvider{{PID: 10, Process: "sshd", Port: 22}},
+		Rules:    DefaultProtectRules(),
+	}
+	entries, err := provider.List(context.Background())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if entries[0].Protection != ProtectConfirm {
+		t.Fatalf("Protection = %q", entries[0].Protection)
+	}
+}
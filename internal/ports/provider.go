@@ -1,6 +1,9 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Port captures a single network port owned by a process.
 type Port struct {
@@ -11,6 +14,55 @@ type Port struct {
 	Port     int
 	Address  string
 	State    string
+
+	// Cmdline is the full command line of the owning process, when known.
+	Cmdline string
+	// StartTime is when the owning process started, when known.
+	StartTime time.Time
+	// Service is a human-readable name for what usually listens on the port.
+	Service string
+	// Unit is the systemd service that owns the process, if any. UserUnit
+	// marks one run by a user's service manager rather than the system's,
+	// and UnitUID is the uid that manager runs as.
+	Unit     string
+	UserUnit bool
+	UnitUID  int
+	// Container names the container or network namespace the socket lives in.
+	Container string
+	// NetNS is the network namespace of a socket outside pzapp's own, as
+	// "net:[INODE]"; empty for sockets of this namespace. Address and Port
+	// are only meaningful inside it, so such sockets cannot be dialled from
+	// here.
+	NetNS string
+	// Host names the remote machine the socket lives on, as RemoteProvider
+	// reports it; empty for this machine.
+	Host string
+	// AppProtocol is the application protocol found by probing the port,
+	// e.g. "HTTP" or "HTTPS". Providers leave it empty.
+	AppProtocol string
+	// Protection is ProtectConfirm or ProtectRefuse when a protection rule,
+	// described by ProtectedBy, matches the process.
+	Protection  string
+	ProtectedBy string
+	// Hidden marks a socket found in the kernel's socket table whose owning
+	// process this user may not inspect. PID is 0 and Process is
+	// HiddenOwner.
+	Hidden bool
+}
+
+// Uptime reports how long the owning process has been running, or zero when
+// the start time is unknown.
+func (p Port) Uptime(now time.Time) time.Duration {
+	if p.StartTime.IsZero() || now.Before(p.StartTime) {
+		return 0
+	}
+	return now.Sub(p.StartTime)
+}
+
+// Reachable reports whether p lives in pzapp's network namespace, so its
+// address can be dialled and its port is the one other processes here see.
+func (p Port) Reachable() bool {
+	return p.NetNS == ""
 }
 
 // Provider enumerates active network ports on the system.
@@ -0,0 +1,204 @@
+package ports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// remoteConnectTimeout bounds establishing the ssh connection.
+	remoteConnectTimeout = 10 * time.Second
+	// psMarker separates the lsof and ps output of the remote listing.
+	psMarker = "--pzapp-ps--"
+	// sshFailed is the exit status ssh reports its own errors with.
+	sshFailed = 255
+)
+
+// Remote is a machine pzapp inspects and kills on over ssh. Authentication
+// must not prompt: ssh runs in batch mode, so keys or an agent are needed.
+type Remote struct {
+	// Target is what ssh connects to: a host, user@host or ssh_config
+	// alias.
+	Target string
+	// SSH is the ssh executable; "ssh" when empty.
+	SSH string
+	// Args are extra ssh options, e.g. ["-p", "2222"]. They come first, so
+	// they override pzapp's own options.
+	Args []string
+}
+
+// Name is the host part of the target, as shown in the header and in
+// Port.Host.
+func (r Remote) Name() string {
+	if _, host, ok := strings.Cut(r.Target, "@"); ok {
+		return host
+	}
+	return r.Target
+}
+
+// Command returns an ssh command running script, a POSIX shell script, on
+// the remote host.
+func (r Remote) Command(ctx context.Context, script string) *exec.Cmd {
+	path := r.SSH
+	if path == "" {
+		path = "ssh"
+	}
+	args := append([]string(nil), r.Args...)
+	args = append(args,
+		"-o", "BatchMode=yes",
+		"-o", fmt.Sprintf("ConnectTimeout=%d", int(remoteConnectTimeout.Seconds())),
+		"--", r.Target, script)
+	return exec.CommandContext(ctx, path, args...)
+}
+
+// run runs script remotely and returns its output. Errors carry the remote
+// stderr, which is where ssh and the script explain themselves.
+func (r Remote) run(ctx context.Context, script string) (string, error) {
+	cmd := r.Command(ctx, script)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err == nil {
+		return string(out), nil
+	}
+	ee := &exec.ExitError{}
+	if !errors.As(err, &ee) {
+		return "", fmt.Errorf("executing %s: %w", cmd.Path, err)
+	}
+	msg := strings.TrimSpace(stderr.String())
+	if msg == "" {
+		msg = ee.Error()
+	}
+	if ee.ExitCode() == sshFailed {
+		return "", fmt.Errorf("ssh %s: %s", r.Target, msg)
+	}
+	return string(out), &RemoteError{Host: r.Name(), Status: ee.ExitCode(), Err: errors.New(msg)}
+}
+
+// RemoteError is a failure of a command run on a remote host.
+type RemoteError struct {
+	Host   string
+	Status int
+	Err    error
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("on %s: %v", e.Host, e.Err)
+}
+
+func (e *RemoteError) Unwrap() error {
+	return e.Err
+}
+
+// RemoteProvider lists a remote host's ports by running lsof and ps there
+// in one ssh session, and parses their output as LsofProvider does. The
+// listing only covers what the ssh user may see, as lsof does locally.
+type RemoteProvider struct {
+	Remote Remote
+}
+
+// listScript runs lsof, then ps for the command lines and start times of
+// every process, since the PIDs are only known once lsof has run. lsof
+// usually lives in sbin, which non-interactive ssh sessions leave off PATH.
+var listScript = `PATH="$PATH:/usr/sbin:/sbin"
+command -v lsof >/dev/null 2>&1 || { echo "lsof is not installed" >&2; exit 127; }
+lsof ` + strings.Join(lsofArgs, " ") + ` 2>/dev/null
+echo '` + psMarker + `'
+ps -eo pid=,etime=,command= 2>/dev/null
+exit 0`
+
+// List runs the discovery commands over ssh.
+func (p RemoteProvider) List(ctx context.Context) ([]Port, error) {
+	out, err := p.Remote.run(ctx, listScript)
+	if err != nil {
+		return nil, err
+	}
+	lsofOut, psOut, ok := strings.Cut(out, psMarker+"\n")
+	if !ok {
+		return nil, fmt.Errorf("on %s: unexpected output from the listing", p.Remote.Name())
+	}
+
+	entries, err := parseLsofOutput(lsofOut)
+	if err != nil {
+		return nil, err
+	}
+	info := parsePsOutput(psOut, time.Now())
+	host := p.Remote.Name()
+	for i := range entries {
+		entries[i].Host = host
+		if proc, ok := info[entries[i].PID]; ok {
+			entries[i].Cmdline = proc.cmdline
+			entries[i].StartTime = proc.started
+		}
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+var _ Provider = RemoteProvider{}
+
+// Exit statuses of the remote kill script.
+const (
+	remoteSignalFailed = 10
+	remoteStillRunning = 11
+	remoteKillFailed   = 12
+	remoteUnstoppable  = 13
+)
+
+// Terminate kills pid on the remote host with policy, like TerminateWith
+// does locally: a shell script sends the signal, waits out the grace period
+// and escalates to SIGKILL if allowed.
+func (r Remote) Terminate(pid int, policy KillPolicy) error {
+	name, err := ParseSignal(policy.Signal)
+	if err != nil {
+		return err
+	}
+	script := fmt.Sprintf(`kill -s %[2]s %[1]d || exit %[4]d
+sleep %[3]s
+kill -0 %[1]d 2>/dev/null || exit 0
+`, pid, name, seconds(policy.Grace), remoteSignalFailed)
+	if policy.Escalate && name != "KILL" {
+		script += fmt.Sprintf(`kill -s KILL %[1]d || exit %[3]d
+sleep %[2]s
+kill -0 %[1]d 2>/dev/null || exit 0
+exit %[4]d`, pid, seconds(policy.KillWait), remoteKillFailed, remoteUnstoppable)
+	} else {
+		script += fmt.Sprintf("exit %d", remoteStillRunning)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteConnectTimeout+policy.Grace+policy.KillWait+10*time.Second)
+	defer cancel()
+	_, err = r.run(ctx, script)
+	var remoteErr *RemoteError
+	if !errors.As(err, &remoteErr) {
+		return err
+	}
+	switch remoteErr.Status {
+	case remoteSignalFailed, remoteKillFailed:
+		signal := name
+		if remoteErr.Status == remoteKillFailed {
+			signal = "KILL"
+		}
+		if strings.Contains(strings.ToLower(remoteErr.Err.Error()), "not permitted") {
+			return &PermissionError{PID: pid, Signal: signal, Err: fmt.Errorf("%w on %s", os.ErrPermission, r.Name())}
+		}
+		return fmt.Errorf("failed to send SIG%s to PID %d: %w", signal, pid, remoteErr)
+	case remoteStillRunning:
+		return fmt.Errorf("process %d on %s is still running after SIG%s", pid, r.Name(), name)
+	case remoteUnstoppable:
+		return fmt.Errorf("process %d on %s survived both SIG%s and SIGKILL - it's unstoppable! 💀", pid, r.Name(), name)
+	}
+	return err
+}
+
+// seconds formats d for sleep(1), which takes fractions on Linux, macOS
+// and busybox alike.
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,141 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSSH puts an ssh on PATH that logs its arguments and runs the remote
+// script locally, plus any extra tools the script should find.
+func fakeSSH(t *testing.T, tools map[string]string) (Remote, string) {
+	t.Helper()
+	dir := t.TempDir()
+	argLog := filepath.Join(dir, "ssh.args")
+	scripts := map[string]string{
+		"ssh": "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argLog + "\nfor last; do :; done\nexec /bin/sh -c \"$last\"\n",
+	}
+	for name, script := range tools {
+		scripts[name] = script
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+":/usr/bin:/bin")
+	return Remote{Target: "deploy@staging", Args: []string{"-p", "2222"}}, argLog
+}
+
+func TestRemoteProviderParsesRemoteListing(t *testing.T) {
+	remote, argLog := fakeSSH(t, map[string]string{
+		"lsof": "#!/bin/sh\nprintf 'p4521\\ncnode\\nLdeploy\\nf20\\nPTCP\\nn*:3000\\nTST=LISTEN\\n'\n",
+		"ps":   "#!/bin/sh\nprintf ' 4521     01:00 node server.js\\n  999     00:05 sshd\\n'\n",
+	})
+
+	entries, err := RemoteProvider{Remote: remote}.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("List() = %+v, want one entry", entries)
+	}
+	got := entries[0]
+	if got.Host != "staging" || got.PID != 4521 || got.Port != 3000 || got.Cmdline != "node server.js" || got.StartTime.IsZero() {
+		t.Fatalf("List() entry = %+v", got)
+	}
+
+	args, err := os.ReadFile(argLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(args), "-p\n2222\n-o\nBatchMode=yes\n") || !strings.Contains(string(args), "--\ndeploy@staging\n") {
+		t.Fatalf("ssh arguments:\n%s", args)
+	}
+}
+
+func TestRemoteProviderReportsMissingLsof(t *testing.T) {
+	remote, _ := fakeSSH(t, map[string]string{
+		"ssh": "#!/bin/sh\nfor last; do :; done\nPATH=/nonexistent exec /bin/sh -c \"$last\"\n",
+	})
+	for _, dir := range []string{"/usr/sbin", "/sbin"} {
+		if _, err := os.Stat(filepath.Join(dir, "lsof")); err == nil {
+			t.Skipf("lsof is installed in %s", dir)
+		}
+	}
+	_, err := RemoteProvider{Remote: remote}.List(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "on staging: lsof is not installed") {
+		t.Fatalf("List() error = %v, want lsof not installed", err)
+	}
+}
+
+func TestRemoteReportsSSHFailures(t *testing.T) {
+	remote, _ := fakeSSH(t, map[string]string{
+		"ssh": "#!/bin/sh\necho 'ssh: Could not resolve hostname staging' >&2\nexit 255\n",
+	})
+	_, err := RemoteProvider{Remote: remote}.List(context.Background())
+	if err == nil || err.Error() != "ssh deploy@staging: ssh: Could not resolve hostname staging" {
+		t.Fatalf("List() error = %v", err)
+	}
+}
+
+func TestRemoteTerminate(t *testing.T) {
+	remote, _ := fakeSSH(t, nil)
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	policy := KillPolicy{Signal: "TERM", Grace: 100 * time.Millisecond, Escalate: true, KillWait: 100 * time.Millisecond}
+	if err := remote.Terminate(cmd.Process.Pid, policy); err != nil {
+		t.Fatalf("Terminate() = %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("process survived the remote kill")
+	}
+
+	err := remote.Terminate(999999, policy)
+	var remoteErr *RemoteError
+	if !errors.As(err, &remoteErr) || !strings.Contains(err.Error(), "failed to send SIGTERM to PID 999999: on staging") {
+		t.Fatalf("Terminate(missing PID) = %v", err)
+	}
+}
+
+func TestRemoteTerminateReportsPermissionError(t *testing.T) {
+	remote, _ := fakeSSH(t, map[string]string{
+		"ssh": "#!/bin/sh\necho 'kill: (1) - Operation not permitted' >&2\nexit 10\n",
+	})
+	err := remote.Terminate(1, DefaultKillPolicy())
+	var permErr *PermissionError
+	if !errors.As(err, &permErr) || !errors.Is(err, os.ErrPermission) {
+		t.Fatalf("Terminate() = %v, want a PermissionError", err)
+	}
+}
+
+func TestDialHostOfRemotePorts(t *testing.T) {
+	for _, tc := range []struct {
+		address string
+		want    string
+	}{
+		{"*", "staging"},
+		{"0.0.0.0", "staging"},
+		{"[::]", "staging"},
+		{"127.0.0.1", "staging"},
+		{"10.0.0.5", "10.0.0.5"},
+		{"[fd00::5]", "fd00::5"},
+	} {
+		if got := (Port{Host: "staging", Address: tc.address}).DialHost(); got != tc.want {
+			t.Errorf("DialHost(%q) = %q, want %q", tc.address, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package ports
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// wellKnownServices names common development and infrastructure ports. Keys
+// are "port" for any protocol or "proto/port" for one protocol. Entries here
+// win over the system services file, whose IANA names are often obscure for
+// development ports (3000 is "hbci").
+var wellKnownServices = map[string]string{
+	"tcp/20":  "ftp-data",
+	"tcp/21":  "ftp",
+	"22":      "ssh",
+	"tcp/23":  "telnet",
+	"tcp/25":  "smtp",
+	"53":      "dns",
+	"udp/67":  "dhcp",
+	"udp/68":  "dhcp-client",
+	"80":      "http",
+	"tcp/110": "pop3",
+	"udp/123": "ntp",
+	"tcp/143": "imap",
+	"udp/161": "snmp",
+	"tcp/389": "ldap",
+	"443":     "https",
+	"tcp/445": "smb",
+	"tcp/465": "smtps",
+	"udp/500": "ipsec",
+	"tcp/587": "submission",
+	"tcp/631": "ipp",
+	"tcp/636": "ldaps",
+	"tcp/993": "imaps",
+	"tcp/995": "pop3s",
+
+	"tcp/1080":  "socks",
+	"tcp/1433":  "mssql",
+	"tcp/1521":  "oracle",
+	"tcp/1883":  "mqtt",
+	"tcp/2049":  "nfs",
+	"tcp/2375":  "docker",
+	"tcp/2376":  "docker-tls",
+	"tcp/2379":  "etcd",
+	"tcp/2380":  "etcd-peer",
+	"tcp/3000":  "React/Next dev",
+	"tcp/3001":  "dev server",
+	"tcp/3306":  "MySQL",
+	"tcp/3389":  "rdp",
+	"udp/3478":  "stun",
+	"tcp/4000":  "Phoenix/Jekyll dev",
+	"tcp/4200":  "Angular dev",
+	"tcp/4222":  "NATS",
+	"tcp/4317":  "OTLP gRPC",
+	"tcp/4318":  "OTLP HTTP",
+	"tcp/4321":  "Astro dev",
+	"tcp/5000":  "Flask/ASP.NET dev",
+	"tcp/5001":  "dev server (TLS)",
+	"udp/5353":  "mdns",
+	"tcp/5173":  "Vite",
+	"tcp/5174":  "Vite",
+	"tcp/5432":  "Postgres",
+	"tcp/5555":  "adb",
+	"tcp/5601":  "Kibana",
+	"tcp/5672":  "RabbitMQ",
+	"tcp/5900":  "vnc",
+	"tcp/6006":  "Storybook",
+	"tcp/6379":  "Redis",
+	"tcp/6443":  "Kubernetes API",
+	"tcp/7000":  "dev server",
+	"tcp/8000":  "Django/http.server",
+	"tcp/8008":  "http-alt",
+	"tcp/8080":  "http-alt",
+	"tcp/8081":  "Metro bundler",
+	"tcp/8086":  "InfluxDB",
+	"tcp/8443":  "https-alt",
+	"tcp/8787":  "Wrangler dev",
+	"tcp/8888":  "Jupyter",
+	"tcp/9000":  "PHP-FPM/MinIO",
+	"tcp/9090":  "Prometheus",
+	"tcp/9092":  "Kafka",
+	"tcp/9100":  "node_exporter",
+	"tcp/9200":  "Elasticsearch",
+	"tcp/9229":  "Node inspector",
+	"tcp/9300":  "Elasticsearch nodes",
+	"tcp/11211": "memcached",
+	"tcp/15672": "RabbitMQ admin",
+	"tcp/19000": "Expo",
+	"tcp/24678": "Vite HMR",
+	"tcp/27017": "Mongo",
+	"tcp/50051": "gRPC",
+}
+
+// systemServicesPath is the IANA-derived services file consulted as a
+// fallback. Tests point it elsewhere.
+var systemServicesPath = "/etc/services"
+
+// ServiceDB maps ports to human-readable service names.
+type ServiceDB struct {
+	overrides map[string]string
+
+	systemOnce sync.Once
+	system     map[string]string
+}
+
+// NewServiceDB returns a database with the built-in names, extended and
+// overridden by overrides. Override keys are "port" or "proto/port".
+func NewServiceDB(overrides map[string]string) (*ServiceDB, error) {
+	db := &ServiceDB{overrides: make(map[string]string, len(overrides))}
+	for key, name := range overrides {
+		normalized, err := ParseServiceKey(key)
+		if err != nil {
+			return nil, err
+		}
+		db.overrides[normalized] = name
+	}
+	return db, nil
+}
+
+// ParseServiceKey validates a "port" or "proto/port" key and returns it in
+// lower case.
+func ParseServiceKey(key string) (string, error) {
+	proto, portText, scoped := strings.Cut(strings.ToLower(strings.TrimSpace(key)), "/")
+	if !scoped {
+		proto, portText = "", proto
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port < 0 || port > 65535 {
+		return "", fmt.Errorf("service key %q: invalid port", key)
+	}
+	switch proto {
+	case "":
+		return portText, nil
+	case "tcp", "udp":
+		return proto + "/" + portText, nil
+	}
+	return "", fmt.Errorf("service key %q: protocol must be tcp or udp", key)
+}
+
+// Lookup returns the service name for a protocol and port, or "".
+func (db *ServiceDB) Lookup(protocol string, port int) string {
+	proto := strings.ToLower(protocol)
+	if strings.HasPrefix(proto, "tcp") {
+		proto = "tcp"
+	} else if strings.HasPrefix(proto, "udp") {
+		proto = "udp"
+	}
+	scoped := proto + "/" + strconv.Itoa(port)
+	bare := strconv.Itoa(port)
+
+	for _, table := range []map[string]string{db.overrides, wellKnownServices, db.systemServices()} {
+		if name, ok := table[scoped]; ok {
+			return name
+		}
+		if name, ok := table[bare]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// Annotate fills in Service for entries that do not have one yet.
+func (db *ServiceDB) Annotate(entries []Port) {
+	for i := range entries {
+		if entries[i].Service == "" {
+			entries[i].Service = db.Lookup(entries[i].Protocol, entries[i].Port)
+		}
+	}
+}
+
+func (db *ServiceDB) systemServices() map[string]string {
+	db.systemOnce.Do(func() {
+		f, err := os.Open(systemServicesPath)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		db.system = parseServices(f)
+	})
+	return db.system
+}
+
+// parseServices reads the services(5) format, "name port/proto [aliases]",
+// into "proto/port" keys. The first name listed for a port wins.
+func parseServices(r io.Reader) map[string]string {
+	services := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		portText, proto, ok := strings.Cut(fields[1], "/")
+		if !ok {
+			continue
+		}
+		key, err := ParseServiceKey(proto + "/" + portText)
+		if err != nil {
+			continue
+		}
+		if _, seen := services[key]; !seen {
+			services[key] = fields[0]
+		}
+	}
+	return services
+}
+
+// ServiceProvider wraps a Provider and names the services on its ports.
+type ServiceProvider struct {
+	Provider
+	Services *ServiceDB
+}
+
+// List returns the wrapped provider's ports with Service filled in.
+func (p ServiceProvider) List(ctx context.Context) ([]Port, error) {
+	entries, err := p.Provider.List(ctx)
+	if err != nil {
+		return entries, err
+	}
+	p.Services.Annotate(entries)
+	return entries, nil
+}
+
+var _ Provider = ServiceProvider{}
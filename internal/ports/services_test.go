@@ -0,0 +1,94 @@
+package ports
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func useServicesFile(t *testing.T, contents string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "services")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	previous := systemServicesPath
+	systemServicesPath = path
+	t.Cleanup(func() { systemServicesPath = previous })
+}
+
+func TestServiceDBLookup(t *testing.T) {
+	useServicesFile(t, `# comment line
+hbci		3000/tcp
+gopher		70/tcp
+gopher-alt	70/tcp				# second name ignored
+syslog		514/udp
+`)
+
+	db, err := NewServiceDB(map[string]string{
+		"8080":     "api gateway",
+		"UDP/5353": "avahi",
+		"tcp/70":   "my gopher",
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	cases := []struct {
+		proto string
+		port  int
+		want  string
+	}{
+		{"TCP", 3000, "React/Next dev"}, // built-in beats /etc/services
+		{"TCP", 5173, "Vite"},
+		{"TCP", 9229, "Node inspector"},
+		{"TCP6", 27017, "Mongo"},
+		{"UDP", 53, "dns"}, // bare key matches any protocol
+		{"TCP", 8080, "api gateway"},
+		{"UDP", 5353, "avahi"},
+		{"TCP", 70, "my gopher"},
+		{"UDP", 514, "syslog"}, // system fallback
+		{"UDP", 3000, ""},      // scoped built-in does not leak
+		{"TCP", 41234, ""},
+	}
+	for _, tc := range cases {
+		if got := db.Lookup(tc.proto, tc.port); got != tc.want {
+			t.Errorf("Lookup(%s, %d) = %q, want %q", tc.proto, tc.port, got, tc.want)
+		}
+	}
+}
+
+func TestParseServiceKeyRejectsInvalid(t *testing.T) {
+	for _, key := range []string{"", "http", "sctp/80", "tcp/70000", "tcp/"} {
+		if _, err := ParseServiceKey(key); err == nil {
+			t.Errorf("ParseServiceKey(%q) succeeded", key)
+		}
+	}
+	if _, err := NewServiceDB(map[string]string{"nope": "x"}); err == nil {
+		t.Fatal("expected invalid override to be rejected")
+	}
+}
+
+func TestServiceProviderAnnotatesList(t *testing.T) {
+	useServicesFile(t, "")
+	db, err := NewServiceDB(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := ServiceProvider{
+		Provider: staticProvider{
+			{Protocol: "TCP", Port: 6379},
+			{Protocol: "TCP", Port: 6379, Service: "cache"},
+		},
+		Services: db,
+	}
+
+	entries, err := provider.List(context.Background())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if entries[0].Service != "Redis" || entries[1].Service != "cache" {
+		t.Fatalf("services = %q, %q", entries[0].Service, entries[1].Service)
+	}
+}
@@ -0,0 +1,112 @@
+package ports
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProcessSnapshot is what it takes to start a process again once it has been
+// killed.
+type ProcessSnapshot struct {
+	Argv []string
+	Cwd  string
+	// Env holds the allowlisted part of the process environment.
+	Env map[string]string
+}
+
+// procRoot is where the proc filesystem is mounted. Tests point it at
+// fixture trees.
+var procRoot = "/proc"
+
+// SnapshotProcess records the argv, working directory and the environment
+// variables matching envAllow (path.Match patterns such as "NODE_*") of pid.
+// It reads /proc where available and falls back to lsof for the working
+// directory elsewhere, in which case Argv and Env stay empty.
+func SnapshotProcess(ctx context.Context, pid int, envAllow []string) (ProcessSnapshot, error) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	if _, err := os.Stat(dir); err != nil {
+		if !errors.Is(err, os.ErrNotExist) || procAvailable() {
+			return ProcessSnapshot{}, fmt.Errorf("snapshot PID %d: %w", pid, err)
+		}
+		cwd, err := lsofCwd(ctx, pid)
+		if err != nil {
+			return ProcessSnapshot{}, fmt.Errorf("snapshot PID %d: %w", pid, err)
+		}
+		return ProcessSnapshot{Cwd: cwd}, nil
+	}
+
+	var snap ProcessSnapshot
+	if data, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		snap.Argv = splitNUL(data)
+	}
+	if cwd, err := os.Readlink(filepath.Join(dir, "cwd")); err == nil {
+		snap.Cwd = cwd
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "environ")); err == nil {
+		snap.Env = filterEnv(splitNUL(data), envAllow)
+	}
+	if len(snap.Argv) == 0 && snap.Cwd == "" {
+		return snap, fmt.Errorf("snapshot PID %d: process details are not readable", pid)
+	}
+	return snap, nil
+}
+
+func procAvailable() bool {
+	_, err := os.Stat(filepath.Join(procRoot, "self"))
+	return err == nil
+}
+
+// lsofCwd asks lsof for the working directory of pid.
+func lsofCwd(ctx context.Context, pid int) (string, error) {
+	out, err := exec.CommandContext(ctx, "lsof", "-a", "-p", strconv.Itoa(pid), "-d", "cwd", "-Fn").Output()
+	if err != nil && len(out) == 0 {
+		return "", fmt.Errorf("lsof cwd: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "n") {
+			return line[1:], nil
+		}
+	}
+	return "", fmt.Errorf("lsof cwd: no working directory reported")
+}
+
+// splitNUL splits a NUL-separated /proc record, dropping the trailing empty
+// element.
+func splitNUL(data []byte) []string {
+	data = bytes.TrimRight(data, "\x00")
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(string(data), "\x00")
+}
+
+// filterEnv keeps the KEY=value pairs whose key matches one of allow.
+func filterEnv(environ, allow []string) map[string]string {
+	env := make(map[string]string)
+	for _, pair := range environ {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		for _, pattern := range allow {
+			if matched, _ := path.Match(pattern, name); matched {
+				env[name] = value
+				break
+			}
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return env
+}
@@ -0,0 +1,152 @@
+package ports
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotProcessReadsProc(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "4242")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"cmdline": "node\x00server.js\x00--port\x003000\x00",
+		"environ": "PATH=/usr/bin\x00NODE_ENV=development\x00NODE_OPTIONS=--inspect\x00AWS_SECRET=hunter2\x00",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("/srv/app", filepath.Join(dir, "cwd")); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = previous })
+
+	snap, err := SnapshotProcess(context.Background(), 4242, []string{"PATH", "NODE_*"})
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if strings.Join(snap.Argv, " ") != "node server.js --port 3000" {
+		t.Fatalf("argv = %q", snap.Argv)
+	}
+	if snap.Cwd != "/srv/app" {
+		t.Fatalf("cwd = %q", snap.Cwd)
+	}
+	if len(snap.Env) != 3 || snap.Env["NODE_OPTIONS"] != "--inspect" || snap.Env["AWS_SECRET"] != "" {
+		t.Fatalf("env = %v", snap.Env)
+	}
+
+	if _, err := SnapshotProcess(context.Background(), 9999, nil); err == nil {
+		t.Fatal("expected missing PID to fail")
+	}
+}
+
+func TestLaunchRunsInCwdWithEnv(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("needs /bin/sh")
+	}
+	dir := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "logs", "relaunch.log")
+
+	pid, err := Launch(ProcessSnapshot{
+		Argv: []string{"/bin/sh", "-c", `echo "$PWD $PZAPP_TEST"`},
+		Cwd:  dir,
+		Env:  map[string]string{"PZAPP_TEST": "relaunched"},
+	}, logPath)
+	if err != nil {
+		t.Fatalf("launch: %v", err)
+	}
+	if pid <= 0 {
+		t.Fatalf("pid = %d", pid)
+	}
+
+	want := dir + " relaunched\n"
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, _ := os.ReadFile(logPath)
+		if string(data) == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("log = %q, want %q", data, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := Launch(ProcessSnapshot{}, ""); err == nil {
+		t.Fatal("expected an empty argv to be rejected")
+	}
+}
+
+func TestLaunchFindsCommandInRecordedPath(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("needs /bin/sh")
+	}
+	// The command lives where only the killed process's PATH points, like
+	// node under nvm or a venv's python.
+	cwd := t.TempDir()
+	bin := filepath.Join(cwd, "node_modules", ".bin")
+	if err := os.MkdirAll(bin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\necho \"served $1\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "pzapp-test-serve"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(t.TempDir(), "relaunch.log")
+
+	_, err := Launch(ProcessSnapshot{
+		Argv: []string{"pzapp-test-serve", "--port=3000"},
+		Cwd:  cwd,
+		Env:  map[string]string{"PATH": "/nonexistent:node_modules/.bin"},
+	}, logPath)
+	if err != nil {
+		t.Fatalf("launch: %v", err)
+	}
+
+	want := "served --port=3000\n"
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, _ := os.ReadFile(logPath)
+		if string(data) == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("log = %q, want %q", data, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if info, err := os.Stat(logPath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("relaunch log = %v, %v; want mode 600", info, err)
+	}
+}
+
+func TestSnapshotCommand(t *testing.T) {
+	cases := []struct {
+		snap ProcessSnapshot
+		want string
+	}{
+		{ProcessSnapshot{Argv: []string{"sh"}, Env: map[string]string{"PATH": "/bin"}}, "/bin/sh"},
+		{ProcessSnapshot{Argv: []string{"./serve"}, Env: map[string]string{"PATH": "/bin"}}, "./serve"},
+		{ProcessSnapshot{Argv: []string{"pzapp-no-such-tool"}, Env: map[string]string{"PATH": "/bin"}}, "pzapp-no-such-tool"},
+		{ProcessSnapshot{Argv: []string{"sh"}}, "sh"},
+	}
+	for _, c := range cases {
+		if got := snapshotCommand(c.snap); got != c.want {
+			t.Errorf("snapshotCommand(%v) = %q, want %q", c.snap, got, c.want)
+		}
+	}
+}
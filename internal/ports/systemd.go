@@ -0,0 +1,128 @@
+package ports
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Unit actions accepted by UnitCommand.
+const (
+	UnitStop    = "stop"
+	UnitRestart = "restart"
+)
+
+// ParseCgroupUnit finds the systemd service owning a process from the
+// contents of /proc/<pid>/cgroup. It reads the unified hierarchy ("0::") or
+// the legacy name=systemd one. Services under a user@UID.service manager are
+// reported with user set and that manager's uid. Scopes, such as login
+// sessions and containers, are not units systemd restarts, so they yield no
+// unit.
+func ParseCgroupUnit(data string) (unit string, user bool, uid int) {
+	var cgroupPath string
+	for _, line := range strings.Split(data, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			cgroupPath = parts[2]
+			break
+		}
+		if parts[1] == "name=systemd" {
+			cgroupPath = parts[2]
+		}
+	}
+
+	for _, segment := range strings.Split(cgroupPath, "/") {
+		if !strings.HasSuffix(segment, ".service") {
+			continue
+		}
+		if manager, ok := strings.CutPrefix(segment, "user@"); ok {
+			// The user manager itself; only services below it count.
+			id, err := strconv.Atoi(strings.TrimSuffix(manager, ".service"))
+			if err != nil {
+				return "", false, 0
+			}
+			unit, user, uid = "", true, id
+			continue
+		}
+		unit = segment
+	}
+	if unit == "" {
+		return "", false, 0
+	}
+	return unit, user, uid
+}
+
+// SystemdUnit returns the service owning pid, if any. Systems without
+// systemd or /proc yield no unit.
+func SystemdUnit(pid int) (unit string, user bool, uid int) {
+	if pid <= 0 {
+		return "", false, 0
+	}
+	data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return "", false, 0
+	}
+	return ParseCgroupUnit(string(data))
+}
+
+// lookupUID is swapped out in tests.
+var lookupUID = user.LookupId
+
+// UnitCommand returns the systemctl command that applies action (UnitStop
+// or UnitRestart) to entry's unit. It is left to the caller to run, since
+// stopping a system unit as a regular user may ask for a password through
+// polkit. A user unit of another account is reached through that user's
+// manager with --machine, which systemctl only allows for root.
+func UnitCommand(ctx context.Context, entry Port, action string) (*exec.Cmd, error) {
+	args := []string{action, entry.Unit}
+	if entry.UserUnit {
+		args = append([]string{"--user"}, args...)
+		if entry.UnitUID != os.Getuid() {
+			owner, err := lookupUID(strconv.Itoa(entry.UnitUID))
+			if err != nil {
+				return nil, fmt.Errorf("find the owner of %s: %w", entry.Unit, err)
+			}
+			args = append([]string{"--machine=" + owner.Username + "@.host"}, args...)
+		}
+	}
+	return exec.CommandContext(ctx, "systemctl", args...), nil
+}
+
+// SystemdProvider wraps a Provider and fills in the systemd unit owning
+// each process.
+type SystemdProvider struct {
+	Provider
+}
+
+// List returns the wrapped provider's ports with Unit filled in.
+func (p SystemdProvider) List(ctx context.Context) ([]Port, error) {
+	entries, err := p.Provider.List(ctx)
+	if err != nil {
+		return entries, err
+	}
+	type owner struct {
+		unit string
+		user bool
+		uid  int
+	}
+	units := make(map[int]owner)
+	for i := range entries {
+		o, ok := units[entries[i].PID]
+		if !ok {
+			o.unit, o.user, o.uid = SystemdUnit(entries[i].PID)
+			units[entries[i].PID] = o
+		}
+		entries[i].Unit, entries[i].UserUnit, entries[i].UnitUID = o.unit, o.user, o.uid
+	}
+	return entries, nil
+}
+
+var _ Provider = SystemdProvider{}
@@ -0,0 +1,122 @@
+package ports
+
+import (
+	"context"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseCgroupUnit(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		unit string
+		user bool
+		uid  int
+	}{
+		{"system service", "0::/system.slice/nginx.service\n", "nginx.service", false, 0},
+		{"user service", "0::/user.slice/user-1000.slice/user@1000.service/app.slice/vite.service\n", "vite.service", true, 1000},
+		{"root user service", "0::/user.slice/user-0.slice/user@0.service/app.slice/vite.service\n", "vite.service", true, 0},
+		{"user manager", "0::/user.slice/user-1000.slice/user@1000.service/init.scope\n", "", false, 0},
+		{"login session", "0::/user.slice/user-1000.slice/session-2.scope\n", "", false, 0},
+		{"container scope", "0::/system.slice/docker-0123abcd.scope\n", "", false, 0},
+		{"legacy hierarchy", "12:pids:/system.slice/sshd.service\n1:name=systemd:/system.slice/sshd.service\n", "sshd.service", false, 0},
+		{"no systemd", "0::/\n", "", false, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			unit, user, uid := ParseCgroupUnit(tc.data)
+			if unit != tc.unit || user != tc.user || uid != tc.uid {
+				t.Errorf("ParseCgroupUnit() = %q, %v, %d; want %q, %v, %d", unit, user, uid, tc.unit, tc.user, tc.uid)
+			}
+		})
+	}
+}
+
+func TestSystemdProviderReadsCgroups(t *testing.T) {
+	root := t.TempDir()
+	cgroups := map[string]string{
+		"100": "0::/system.slice/postgresql.service\n",
+		"200": "0::/user.slice/user-1000.slice/session-3.scope\n",
+	}
+	for pid, data := range cgroups {
+		if err := os.MkdirAll(filepath.Join(root, pid), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, pid, "cgroup"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	previous := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = previous })
+
+	provider := SystemdProvider{Provider: staticProvider{
+		{PID: 100, Port: 5432}, {PID: 100, Port: 5433}, {PID: 200, Port: 3000}, {PID: 300, Port: 8080},
+	}}
+	entries, err := provider.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"postgresql.service", "postgresql.service", "", ""}
+	for i, entry := range entries {
+		if entry.Unit != want[i] {
+			t.Errorf("entries[%d].Unit = %q, want %q", i, entry.Unit, want[i])
+		}
+	}
+}
+
+func TestUnitCommandRunsSystemctl(t *testing.T) {
+	dir := t.TempDir()
+	record := filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" >> " + record + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "systemctl"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	other := os.Getuid() + 1
+	previous := lookupUID
+	lookupUID = func(uid string) (*user.User, error) {
+		if uid != strconv.Itoa(other) {
+			return nil, user.UnknownUserIdError(other)
+		}
+		return &user.User{Uid: uid, Username: "alice"}, nil
+	}
+	t.Cleanup(func() { lookupUID = previous })
+
+	ctx := context.Background()
+	for _, run := range []struct {
+		entry  Port
+		action string
+	}{
+		{Port{Unit: "nginx.service"}, UnitStop},
+		{Port{Unit: "vite.service", UserUnit: true, UnitUID: os.Getuid()}, UnitRestart},
+		{Port{Unit: "api.service", UserUnit: true, UnitUID: other}, UnitStop},
+	} {
+		cmd, err := UnitCommand(ctx, run.entry, run.action)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cmd.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []string{"stop nginx.service", "--user restart vite.service", "--machine=alice@.host --user stop api.service"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("systemctl ran with %q, want %q", got, want)
+	}
+
+	if _, err := UnitCommand(ctx, Port{Unit: "gone.service", UserUnit: true, UnitUID: other + 1}, UnitStop); err == nil {
+		t.Error("UnitCommand() for an unknown uid succeeded, want an error")
+	}
+}
@@ -0,0 +1,84 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// waitInterval is how often WaitPort checks the port.
+const waitInterval = 200 * time.Millisecond
+
+// WaitPort polls until p's port is listening (up) or free (!up), or ctx
+// ends. TCP ports are checked by connecting and UDP ports by trying to bind
+// them, since nothing answers a UDP dial.
+func WaitPort(ctx context.Context, p Port, up bool) error {
+	ticker := time.NewTicker(waitInterval)
+	defer ticker.Stop()
+	for {
+		if Listening(p) == up {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			state := "free"
+			if up {
+				state = "listening"
+			}
+			return fmt.Errorf("%s port %d not %s: %w", strings.ToUpper(p.Protocol), p.Port, state, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Listening reports whether something currently holds p's port.
+func Listening(p Port) bool {
+	if strings.EqualFold(p.Protocol, "udp") {
+		host := p.Address
+		if host == "*" {
+			host = ""
+		}
+		conn, err := net.ListenPacket("udp", net.JoinHostPort(host, strconv.Itoa(p.Port)))
+		if err != nil {
+			return errors.Is(err, syscall.EADDRINUSE)
+		}
+		conn.Close()
+		return false
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(p.DialHost(), strconv.Itoa(p.Port)), waitInterval)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// PortFree reports whether a TCP listener could bind port on every
+// address, which is stricter than nothing answering on loopback.
+func PortFree(port int) bool {
+	ln, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		// Privileged ports cannot be bound without root; nothing answering
+		// is the best available answer.
+		return errors.Is(err, syscall.EACCES) && !Listening(Port{Protocol: "tcp", Port: port})
+	}
+	ln.Close()
+	return true
+}
+
+// NextFreePort returns the first port from start up for which PortFree
+// holds.
+func NextFreePort(start int) (int, error) {
+	for port := max(start, 1); port <= 65535; port++ {
+		if PortFree(port) {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free port from %d up", start)
+}
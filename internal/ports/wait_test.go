@@ -0,0 +1,69 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWaitPortTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Port{Protocol: "tcp", Port: ln.Addr().(*net.TCPAddr).Port, Address: "127.0.0.1"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := WaitPort(ctx, p, true); err != nil {
+		t.Fatalf("WaitPort(up) with a listener: %v", err)
+	}
+
+	time.AfterFunc(300*time.Millisecond, func() { ln.Close() })
+	if err := WaitPort(ctx, p, false); err != nil {
+		t.Fatalf("WaitPort(free) after closing: %v", err)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancelShort()
+	if err := WaitPort(short, p, true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitPort(up) on a closed port = %v, want a deadline error", err)
+	}
+}
+
+func TestListeningUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Port{Protocol: "udp", Port: conn.LocalAddr().(*net.UDPAddr).Port, Address: "127.0.0.1"}
+	if !Listening(p) {
+		t.Fatal("Listening() = false for a bound UDP socket")
+	}
+	conn.Close()
+	if Listening(p) {
+		t.Fatal("Listening() = true after closing the UDP socket")
+	}
+}
+
+func TestNextFreePortSkipsTakenPorts(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	taken := ln.Addr().(*net.TCPAddr).Port
+
+	port, err := NextFreePort(taken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port <= taken {
+		t.Fatalf("NextFreePort(%d) = %d, want a later port", taken, port)
+	}
+	if _, err := NextFreePort(70000); err == nil {
+		t.Fatal("NextFreePort(70000) succeeded")
+	}
+}
@@ -0,0 +1,81 @@
+// Package prefs persists small pieces of UI state between pzapp sessions.
+package prefs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"portkiller/internal/query"
+)
+
+// Prefs is the state remembered across sessions. Nil fields have never been
+// saved and should fall back to the configured defaults.
+type Prefs struct {
+	Toggles *query.Toggles `json:"toggles,omitempty"`
+}
+
+// Path returns the location of the prefs file, honouring $XDG_STATE_HOME and
+// falling back to ~/.local/state.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("locate state directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "pzapp", "prefs.json"), nil
+}
+
+// Load reads the saved prefs. A missing file yields zero-value Prefs.
+func Load() (Prefs, error) {
+	var p Prefs
+
+	path, err := Path()
+	if err != nil {
+		return p, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return p, nil
+		}
+		return p, fmt.Errorf("read prefs: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Prefs{}, fmt.Errorf("parse prefs %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Save writes the prefs atomically, creating the state directory if needed.
+func Save(p Prefs) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode prefs: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write prefs: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write prefs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package prefs
+
+import (
+	"testing"
+
+	"portkiller/internal/query"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	empty, err := Load()
+	if err != nil {
+		t.Fatalf("load without file: %v", err)
+	}
+	if empty.Toggles != nil {
+		t.Fatalf("expected no saved toggles, got %+v", *empty.Toggles)
+	}
+
+	want := query.Toggles{MineOnly: true, HideUDP: true}
+	if err := Save(Prefs{Toggles: &want}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	got, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.Toggles == nil || *got.Toggles != want {
+		t.Fatalf("round trip mismatch: got %+v want %+v", got.Toggles, want)
+	}
+}
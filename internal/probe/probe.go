@@ -0,0 +1,175 @@
+// Package probe talks to a local listener to find out whether it serves HTTP
+// or TLS, and what kind of server is behind it.
+package probe
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Result describes what answered on a port.
+type Result struct {
+	// TLS is set when the listener completed a TLS handshake.
+	TLS bool
+	// SelfSigned is set when the TLS leaf certificate signed itself.
+	SelfSigned bool
+	// Status is the HTTP status code, or zero if no HTTP response was read.
+	Status int
+	// Server names the software behind the port when it could be recognised,
+	// e.g. "Vite dev server" or "nginx/1.25.3".
+	Server string
+}
+
+// Protocol returns "HTTPS", "HTTP", "TLS" or "" for the detected protocol.
+func (r Result) Protocol() string {
+	switch {
+	case r.TLS && r.Status > 0:
+		return "HTTPS"
+	case r.Status > 0:
+		return "HTTP"
+	case r.TLS:
+		return "TLS"
+	}
+	return ""
+}
+
+// Scheme is the URL scheme for opening the port in a browser.
+func (r Result) Scheme() string {
+	if r.TLS {
+		return "https"
+	}
+	return "http"
+}
+
+// String summarises the result, e.g. "HTTP 200 · Vite dev server" or
+// "TLS · self-signed".
+func (r Result) String() string {
+	head := r.Protocol()
+	if head == "" {
+		return "no HTTP or TLS response"
+	}
+	if r.Status > 0 {
+		head += " " + strconv.Itoa(r.Status)
+	}
+	parts := []string{head}
+	if r.Server != "" {
+		parts = append(parts, r.Server)
+	}
+	if r.SelfSigned {
+		parts = append(parts, "self-signed")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// maxBody bounds how much of the response body is read for fingerprints.
+const maxBody = 64 << 10
+
+// HTTP probes host:port. It first attempts a TLS handshake and then issues a
+// GET / over TLS or plain HTTP. An error is returned only when neither TLS nor
+// HTTP answered; the deadline comes from ctx.
+func HTTP(ctx context.Context, host string, port int) (Result, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	var result Result
+
+	dialer := &tls.Dialer{Config: &tls.Config{InsecureSkipVerify: true}}
+	if conn, err := dialer.DialContext(ctx, "tcp", addr); err == nil {
+		state := conn.(*tls.Conn).ConnectionState()
+		result.TLS = true
+		result.SelfSigned = selfSigned(state.PeerCertificates)
+		conn.Close()
+	} else if ctx.Err() != nil {
+		return result, fmt.Errorf("probe %s: %w", addr, ctx.Err())
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:             nil,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.Scheme()+"://"+addr+"/", nil)
+	if err != nil {
+		return result, fmt.Errorf("probe %s: %w", addr, err)
+	}
+	req.Header.Set("User-Agent", "pzapp-probe")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if result.TLS {
+			return result, nil
+		}
+		return result, fmt.Errorf("probe %s: no HTTP or TLS response: %w", addr, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		body = nil
+	}
+	result.Status = resp.StatusCode
+	result.Server = identify(resp.Header, body)
+	return result, nil
+}
+
+// selfSigned reports whether the leaf certificate is its own issuer.
+func selfSigned(certs []*x509.Certificate) bool {
+	if len(certs) == 0 {
+		return false
+	}
+	leaf := certs[0]
+	if !bytes.Equal(leaf.RawIssuer, leaf.RawSubject) {
+		return false
+	}
+	return leaf.CheckSignatureFrom(leaf) == nil
+}
+
+// bodyMarkers recognise common development servers that do not announce
+// themselves in headers.
+var bodyMarkers = []struct {
+	needle string
+	name   string
+}{
+	{"/@vite/client", "Vite dev server"},
+	{"webpack-dev-server", "webpack dev server"},
+	{"__NEXT_DATA__", "Next.js"},
+	{"/_next/static", "Next.js"},
+	{"ng-version=", "Angular"},
+	{"Jupyter Server", "Jupyter"},
+	{"Directory listing for", "Python http.server"},
+}
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>\s*([^<]{1,80}?)\s*</title>`)
+
+// identify names the server from body markers, then headers, then the page
+// title.
+func identify(header http.Header, body []byte) string {
+	for _, marker := range bodyMarkers {
+		if bytes.Contains(body, []byte(marker.needle)) {
+			return marker.name
+		}
+	}
+	if powered := header.Get("X-Powered-By"); powered != "" {
+		return powered
+	}
+	if server := header.Get("Server"); server != "" {
+		return server
+	}
+	if match := titlePattern.FindSubmatch(body); match != nil {
+		return strings.Join(strings.Fields(string(match[1])), " ")
+	}
+	return ""
+}
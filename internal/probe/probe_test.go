@@ -0,0 +1,134 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func hostPort(t *testing.T, rawURL string) (string, int) {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Hostname(), port
+}
+
+func probe(t *testing.T, rawURL string) (Result, error) {
+	t.Helper()
+	host, port := hostPort(t, rawURL)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return HTTP(ctx, host, port)
+}
+
+func TestHTTPDetectsViteDevServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><script type="module" src="/@vite/client"></script><title>app</title></head></html>`)
+	}))
+	defer srv.Close()
+
+	got, err := probe(t, srv.URL)
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if got.String() != "HTTP 200 · Vite dev server" {
+		t.Fatalf("String() = %q", got.String())
+	}
+	if got.Scheme() != "http" {
+		t.Fatalf("Scheme() = %q", got.Scheme())
+	}
+}
+
+func TestHTTPFallsBackToHeadersAndTitle(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{"server header", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Server", "nginx/1.25.3")
+			w.WriteHeader(http.StatusNotFound)
+		}, "HTTP 404 · nginx/1.25.3"},
+		{"powered by", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Powered-By", "Express")
+		}, "HTTP 200 · Express"},
+		{"title", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "<title>\n  Grafana\n</title>")
+		}, "HTTP 200 · Grafana"},
+		{"redirect not followed", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/login", http.StatusFound)
+		}, "HTTP 302"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(tc.handler)
+			defer srv.Close()
+			got, err := probe(t, srv.URL)
+			if err != nil {
+				t.Fatalf("probe: %v", err)
+			}
+			if got.String() != tc.want {
+				t.Fatalf("String() = %q, want %q", got.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestHTTPDetectsSelfSignedTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	got, err := probe(t, srv.URL)
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if !got.TLS || !got.SelfSigned || got.Protocol() != "HTTPS" || got.Scheme() != "https" {
+		t.Fatalf("result = %+v", got)
+	}
+	if got.String() != "HTTPS 204 · self-signed" {
+		t.Fatalf("String() = %q", got.String())
+	}
+}
+
+func TestHTTPReportsSilentListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	got, err := HTTP(ctx, "127.0.0.1", port)
+	if err == nil {
+		t.Fatalf("expected an error, got %+v", got)
+	}
+	if got.Protocol() != "" || got.String() != "no HTTP or TLS response" {
+		t.Fatalf("result = %+v", got)
+	}
+}
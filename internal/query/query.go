@@ -0,0 +1,280 @@
+// Package query implements the structured filter language used to narrow the
+// port list, e.g. `port:3000-3999 proc:node user:!root proto:udp`.
+package query
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"portkiller/internal/ports"
+)
+
+// Query is a parsed filter expression. The zero value matches every port.
+type Query struct {
+	raw   string
+	terms []term
+}
+
+type term struct {
+	negate bool
+	match  func(ports.Port) bool
+}
+
+// matcherFunc builds a predicate for a single comma-separated alternative.
+type matcherFunc func(value string) (func(ports.Port) bool, error)
+
+var fields = map[string]matcherFunc{
+	"port":      portMatcher,
+	"pid":       pidMatcher,
+	"proc":      substringMatcher(func(p ports.Port) string { return p.Process }),
+	"user":      globMatcher(func(p ports.Port) string { return p.User }),
+	"proto":     globMatcher(func(p ports.Port) string { return p.Protocol }),
+	"addr":      globMatcher(func(p ports.Port) string { return p.Address }),
+	"state":     prefixMatcher(func(p ports.Port) string { return p.State }),
+	"service":   substringMatcher(func(p ports.Port) string { return p.Service }),
+	"unit":      substringMatcher(func(p ports.Port) string { return p.Unit }),
+	"container": substringMatcher(func(p ports.Port) string { return p.Container }),
+}
+
+// Fields lists the field names accepted by Parse, sorted alphabetically.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse turns a whitespace-separated list of `field:value` terms into a Query.
+// Values may be prefixed with `!` to negate them and may list alternatives
+// separated by commas. Bare words match the process, user, address or
+// service.
+func Parse(input string) (Query, error) {
+	q := Query{raw: strings.TrimSpace(input)}
+	for _, token := range strings.Fields(input) {
+		t, err := parseTerm(token)
+		if err != nil {
+			return Query{}, err
+		}
+		q.terms = append(q.terms, t)
+	}
+	return q, nil
+}
+
+func parseTerm(token string) (term, error) {
+	field, value, ok := strings.Cut(token, ":")
+	if !ok {
+		field, value = "", token
+	}
+	field = strings.ToLower(field)
+
+	var t term
+	if strings.HasPrefix(value, "!") {
+		t.negate = true
+		value = value[1:]
+	}
+	if value == "" {
+		return term{}, fmt.Errorf("term %q: missing value", token)
+	}
+
+	build := bareMatcher
+	if field != "" {
+		var known bool
+		build, known = fields[field]
+		if !known {
+			return term{}, fmt.Errorf("term %q: unknown field %q (want one of %s)", token, field, strings.Join(Fields(), ", "))
+		}
+	}
+
+	var alternatives []func(ports.Port) bool
+	for _, alt := range strings.Split(value, ",") {
+		if alt == "" {
+			continue
+		}
+		match, err := build(alt)
+		if err != nil {
+			return term{}, fmt.Errorf("term %q: %w", token, err)
+		}
+		alternatives = append(alternatives, match)
+	}
+	if len(alternatives) == 0 {
+		return term{}, fmt.Errorf("term %q: missing value", token)
+	}
+
+	t.match = func(p ports.Port) bool {
+		for _, match := range alternatives {
+			if match(p) {
+				return true
+			}
+		}
+		return false
+	}
+	return t, nil
+}
+
+// Match reports whether the port satisfies every term in the query.
+func (q Query) Match(p ports.Port) bool {
+	for _, t := range q.terms {
+		if t.match(p) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the subset of entries matched by the query.
+func (q Query) Filter(entries []ports.Port) []ports.Port {
+	if q.Empty() {
+		return entries
+	}
+	filtered := make([]ports.Port, 0, len(entries))
+	for _, entry := range entries {
+		if q.Match(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// Empty reports whether the query has no terms.
+func (q Query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+// String returns the query as originally typed.
+func (q Query) String() string {
+	return q.raw
+}
+
+func portMatcher(value string) (func(ports.Port) bool, error) {
+	low, high, err := parseRange(value)
+	if err != nil {
+		return nil, err
+	}
+	return func(p ports.Port) bool { return p.Port >= low && p.Port <= high }, nil
+}
+
+func pidMatcher(value string) (func(ports.Port) bool, error) {
+	low, high, err := parseRange(value)
+	if err != nil {
+		return nil, err
+	}
+	return func(p ports.Port) bool { return p.PID >= low && p.PID <= high }, nil
+}
+
+// parseRange accepts `N`, `N-M`, `N-` and `-M`.
+func parseRange(value string) (int, int, error) {
+	lowText, highText, isRange := strings.Cut(value, "-")
+	if !isRange {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid number %q", value)
+		}
+		return n, n, nil
+	}
+
+	low, high := 0, int(^uint(0)>>1)
+	if lowText != "" {
+		n, err := strconv.Atoi(lowText)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid range %q", value)
+		}
+		low = n
+	}
+	if highText != "" {
+		n, err := strconv.Atoi(highText)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid range %q", value)
+		}
+		high = n
+	}
+	if low > high {
+		return 0, 0, fmt.Errorf("invalid range %q: start exceeds end", value)
+	}
+	return low, high, nil
+}
+
+func substringMatcher(get func(ports.Port) string) matcherFunc {
+	return func(value string) (func(ports.Port) bool, error) {
+		needle := strings.ToLower(value)
+		return func(p ports.Port) bool {
+			return strings.Contains(strings.ToLower(get(p)), needle)
+		}, nil
+	}
+}
+
+func globMatcher(get func(ports.Port) string) matcherFunc {
+	return func(value string) (func(ports.Port) bool, error) {
+		pattern := strings.ToLower(value)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q", value)
+		}
+		return func(p ports.Port) bool {
+			ok, _ := path.Match(pattern, strings.ToLower(get(p)))
+			return ok
+		}, nil
+	}
+}
+
+func prefixMatcher(get func(ports.Port) string) matcherFunc {
+	return func(value string) (func(ports.Port) bool, error) {
+		prefix := strings.ToLower(value)
+		return func(p ports.Port) bool {
+			return strings.HasPrefix(strings.ToLower(get(p)), prefix)
+		}, nil
+	}
+}
+
+func bareMatcher(value string) (func(ports.Port) bool, error) {
+	needle := strings.ToLower(value)
+	return func(p ports.Port) bool {
+		for _, text := range []string{p.Process, p.User, p.Address, p.Service} {
+			if strings.Contains(strings.ToLower(text), needle) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// Toggles are the one-key quick filters layered on top of a Query.
+type Toggles struct {
+	MineOnly     bool `json:"mine_only"`
+	HideSystem   bool `json:"hide_system"`
+	HideLoopback bool `json:"hide_loopback"`
+	HideUDP      bool `json:"hide_udp"`
+}
+
+// Match reports whether the port survives the enabled toggles. currentUser is
+// the login name compared against the port owner when MineOnly is set.
+func (t Toggles) Match(p ports.Port, currentUser string) bool {
+	switch {
+	case t.MineOnly && !strings.EqualFold(p.User, currentUser):
+		return false
+	case t.HideSystem && p.Port < 1024:
+		return false
+	case t.HideLoopback && isLoopback(p.Address):
+		return false
+	case t.HideUDP && strings.HasPrefix(strings.ToLower(p.Protocol), "udp"):
+		return false
+	}
+	return true
+}
+
+// Any reports whether at least one toggle is enabled.
+func (t Toggles) Any() bool {
+	return t.MineOnly || t.HideSystem || t.HideLoopback || t.HideUDP
+}
+
+func isLoopback(addr string) bool {
+	if strings.EqualFold(addr, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.IsLoopback()
+}
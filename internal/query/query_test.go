@@ -0,0 +1,124 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"portkiller/internal/ports"
+)
+
+func TestQueryMatch(t *testing.T) {
+	entries := []ports.Port{
+		{PID: 4521, Process: "node", User: "naveed", Protocol: "tcp", Port: 3000, Address: "127.0.0.1", State: "LISTEN", Service: "React/Next dev"},
+		{PID: 13000, Process: "postgres", User: "postgres", Protocol: "tcp", Port: 5432, Address: "127.0.0.1", State: "LISTEN", Service: "Postgres", Unit: "postgresql.service"},
+		{PID: 8871, Process: "nginx", User: "root", Protocol: "tcp", Port: 443, Address: "0.0.0.0", State: "LISTEN"},
+		{PID: 3333, Process: "avahi-daemon", User: "root", Protocol: "udp", Port: 5353, Address: "*"},
+		{PID: 9001, Process: "nginx", User: "root", Protocol: "tcp", Port: 80, Address: "*", State: "LISTEN", Container: "docker:3f2a1b9c4d5e"},
+	}
+
+	cases := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{3000, 5432, 443, 5353, 80}},
+		{"port:3000", []int{3000}},
+		{"port:3000-5999", []int{3000, 5432, 5353}},
+		{"port:-1023", []int{443, 80}},
+		{"port:!3000-5999", []int{443, 80}},
+		{"port:443,3000", []int{3000, 443}},
+		{"proc:NODE", []int{3000}},
+		{"user:!root", []int{3000, 5432}},
+		{"proto:udp", []int{5353}},
+		{"addr:127.0.0.1 state:listen", []int{3000, 5432}},
+		{"addr:127.*", []int{3000, 5432}},
+		{"pid:13000", []int{5432}},
+		{"root proto:tcp", []int{443, 80}},
+		{"service:next", []int{3000}},
+		{"service:!postgres", []int{3000, 443, 5353, 80}},
+		{"react", []int{3000}},
+		{"unit:postgresql", []int{5432}},
+		{"container:docker", []int{80}},
+		{"proc:nginx container:!docker", []int{443}},
+	}
+
+	for _, tc := range cases {
+		q, err := Parse(tc.query)
+		if err != nil {
+			t.Fatalf("Parse(%q): unexpected error: %v", tc.query, err)
+		}
+		var got []int
+		for _, entry := range q.Filter(entries) {
+			got = append(got, entry.Port)
+		}
+		if !equalInts(got, tc.want) {
+			t.Errorf("Parse(%q) matched %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"colour:red":     "unknown field",
+		"port:abc":       "invalid number",
+		"port:9000-8000": "start exceeds end",
+		"user:":          "missing value",
+		"proc:!":         "missing value",
+		"addr:[":         "invalid pattern",
+	}
+
+	for input, want := range cases {
+		_, err := Parse(input)
+		if err == nil {
+			t.Fatalf("Parse(%q): expected error", input)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error %q, want it to mention %q", input, err, want)
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTogglesMatch(t *testing.T) {
+	entries := []ports.Port{
+		{PID: 1, Process: "node", User: "naveed", Protocol: "tcp", Port: 3000, Address: "127.0.0.1"},
+		{PID: 2, Process: "vite", User: "naveed", Protocol: "tcp6", Port: 5173, Address: "::1"},
+		{PID: 3, Process: "sshd", User: "root", Protocol: "tcp", Port: 22, Address: "0.0.0.0"},
+		{PID: 4, Process: "mdns", User: "naveed", Protocol: "udp", Port: 5353, Address: "*"},
+	}
+
+	cases := []struct {
+		name    string
+		toggles Toggles
+		want    []int
+	}{
+		{"none", Toggles{}, []int{1, 2, 3, 4}},
+		{"mine", Toggles{MineOnly: true}, []int{1, 2, 4}},
+		{"system", Toggles{HideSystem: true}, []int{1, 2, 4}},
+		{"loopback", Toggles{HideLoopback: true}, []int{3, 4}},
+		{"udp", Toggles{HideUDP: true}, []int{1, 2, 3}},
+		{"combined", Toggles{MineOnly: true, HideLoopback: true, HideUDP: true}, nil},
+	}
+
+	for _, tc := range cases {
+		var got []int
+		for _, entry := range entries {
+			if tc.toggles.Match(entry, "naveed") {
+				got = append(got, entry.PID)
+			}
+		}
+		if !equalInts(got, tc.want) {
+			t.Errorf("%s: matched %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"portkiller/internal/config"
+	"portkiller/internal/ports"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// column describes one table column: its header, width spec and how to render
+// a cell for a port.
+type column struct {
+	name    string
+	title   string
+	min     int
+	desired int
+	// priority decides which columns survive on narrow terminals; the lowest
+	// priority column is dropped first.
+	priority int
+	render   func(p ports.Port, ctx cellContext) string
+	// compare orders two ports by this column for header-click sorting.
+	compare func(a, b ports.Port) int
+}
+
+// cellContext carries row-independent state needed while rendering cells.
+type cellContext struct {
+	now       time.Time
+	showState bool
+}
+
+// columnCatalog holds every known column. Widths include the cell icon.
+var columnCatalog = map[string]column{
+	"proto":     {name: "proto", title: "PROTO", min: 6, desired: 11, priority: 70, render: renderProtoCell, compare: byText(func(p ports.Port) string { return p.Protocol })},
+	"port":      {name: "port", title: "PORT", min: 8, desired: 9, priority: 100, render: renderPortCell, compare: byNumber(func(p ports.Port) int { return p.Port })},
+	"process":   {name: "process", title: "PROCESS", min: 14, desired: 22, priority: 90, render: renderProcessCell, compare: byText(func(p ports.Port) string { return p.Process })},
+	"pid":       {name: "pid", title: "PID", min: 8, desired: 10, priority: 80, render: renderPIDCell, compare: byNumber(func(p ports.Port) int { return p.PID })},
+	"user":      {name: "user", title: "USER", min: 10, desired: 14, priority: 60, render: renderUserCell, compare: byText(func(p ports.Port) string { return p.User })},
+	"address":   {name: "address", title: "ADDRESS", min: 18, desired: 30, priority: 50, render: renderAddressCell, compare: byText(func(p ports.Port) string { return p.Address })},
+	"state":     {name: "state", title: "STATE", min: 10, desired: 14, priority: 40, render: renderStateCell, compare: byText(func(p ports.Port) string { return p.State })},
+	"service":   {name: "service", title: "SERVICE", min: 12, desired: 20, priority: 35, render: renderServiceCell, compare: byText(func(p ports.Port) string { return p.Service })},
+	"unit":      {name: "unit", title: "UNIT", min: 12, desired: 24, priority: 25, render: renderUnitCell, compare: byText(func(p ports.Port) string { return p.Unit })},
+	"container": {name: "container", title: "CONTAINER", min: 12, desired: 18, priority: 30, render: renderContainerCell, compare: byText(func(p ports.Port) string { return p.Container })},
+	"uptime":    {name: "uptime", title: "UPTIME", min: 8, desired: 10, priority: 20, render: renderUptimeCell, compare: compareUptime},
+	"cmdline":   {name: "cmdline", title: "CMDLINE", min: 16, desired: 48, priority: 10, render: renderCmdlineCell, compare: byText(func(p ports.Port) string { return p.Cmdline })},
+	"host":      {name: "host", title: "HOST", min: 10, desired: 18, priority: 95, render: renderHostCell, compare: byText(func(p ports.Port) string { return p.Host })},
+}
+
+// columnLayout is the set of visible columns and their computed widths. It is
+// shared by pointer between the model and every list item so a resize
+// re-lays out rows without rebuilding them.
+type columnLayout struct {
+	configured []column
+	visible    []column
+	widths     []int
+}
+
+const (
+	columnSeparator = " | "
+	rowSeparator    = " ┃ "
+	rowPrefix       = "▶ "
+)
+
+func newColumnLayout(names []string, overrides map[string]config.ColumnWidth) *columnLayout {
+	layout := &columnLayout{}
+	for _, name := range names {
+		col, ok := columnCatalog[name]
+		if !ok {
+			continue
+		}
+		if override, ok := overrides[name]; ok {
+			if override.Min > 0 {
+				col.min = override.Min
+			}
+			if override.Desired > 0 {
+				col.desired = override.Desired
+			}
+			col.desired = max(col.desired, col.min)
+		}
+		layout.configured = append(layout.configured, col)
+	}
+	if len(layout.configured) == 0 {
+		layout.configured = append(layout.configured, columnCatalog["port"])
+	}
+
+	layout.visible = layout.configured
+	layout.widths = make([]int, len(layout.visible))
+	for i, col := range layout.visible {
+		layout.widths[i] = col.desired
+	}
+	return layout
+}
+
+// columnAt returns the index of the visible column under screen column x, or
+// -1 when x falls on the row prefix or a separator.
+func (l *columnLayout) columnAt(x int) int {
+	separatorWidth := lipgloss.Width(columnSeparator)
+	start := lipgloss.Width(rowPrefix) + 1 // delegate left padding
+	for i, width := range l.widths {
+		if x >= start && x < start+width {
+			return i
+		}
+		start += width + separatorWidth
+	}
+	return -1
+}
+
+// has reports whether the named column is currently visible.
+func (l *columnLayout) has(name string) bool {
+	for _, col := range l.visible {
+		if col.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fit chooses visible columns and widths for the given row width. Columns are
+// dropped lowest-priority first until the minimum widths fit, then each
+// column grows towards its desired width and any slack is shared out.
+func (l *columnLayout) fit(width int) {
+	separatorWidth := lipgloss.Width(columnSeparator)
+	chrome := lipgloss.Width(rowPrefix) + 1 // delegate left padding
+
+	visible := append([]column(nil), l.configured...)
+	available := func() int {
+		return width - chrome - separatorWidth*(len(visible)-1)
+	}
+	minimum := func() int {
+		total := 0
+		for _, col := range visible {
+			total += col.min
+		}
+		return total
+	}
+
+	for len(visible) > 1 && minimum() > available() {
+		drop := 0
+		for i, col := range visible {
+			if col.priority < visible[drop].priority {
+				drop = i
+			}
+		}
+		visible = append(visible[:drop], visible[drop+1:]...)
+	}
+
+	widths := make([]int, len(visible))
+	remaining := available()
+	for i, col := range visible {
+		widths[i] = col.min
+		remaining -= col.min
+	}
+	if remaining < 0 {
+		// A lone column that still does not fit is squeezed.
+		widths[0] = max(1, widths[0]+remaining)
+		remaining = 0
+	}
+
+	for remaining > 0 {
+		progress := false
+		for i, col := range visible {
+			if remaining == 0 {
+				break
+			}
+			if widths[i] >= col.desired {
+				continue
+			}
+			add := min(col.desired-widths[i], remaining)
+			widths[i] += add
+			remaining -= add
+			progress = true
+		}
+		if !progress {
+			for i := range widths {
+				if remaining == 0 {
+					break
+				}
+				widths[i]++
+				remaining--
+			}
+		}
+	}
+
+	l.visible = visible
+	l.widths = widths
+}
+
+// row renders a single-line table row for the port.
+func (l *columnLayout) row(p ports.Port) string {
+	ctx := cellContext{now: time.Now(), showState: l.has("state")}
+	cells := make([]string, len(l.visible))
+	for i, col := range l.visible {
+		cells[i] = padded(col.render(p, ctx), l.widths[i])
+	}
+	return rowPrefix + strings.Join(cells, rowSeparator)
+}
+
+// sortPorts orders entries in place by the named column. Unknown names leave
+// the provider order untouched.
+func sortPorts(entries []ports.Port, name string, descending bool) {
+	col, ok := columnCatalog[name]
+	if !ok {
+		return
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if descending {
+			return col.compare(entries[j], entries[i]) < 0
+		}
+		return col.compare(entries[i], entries[j]) < 0
+	})
+}
+
+func byText(get func(ports.Port) string) func(a, b ports.Port) int {
+	return func(a, b ports.Port) int {
+		return strings.Compare(strings.ToLower(get(a)), strings.ToLower(get(b)))
+	}
+}
+
+func byNumber(get func(ports.Port) int) func(a, b ports.Port) int {
+	return func(a, b ports.Port) int {
+		return get(a) - get(b)
+	}
+}
+
+// compareUptime sorts the youngest process first; unknown start times last.
+func compareUptime(a, b ports.Port) int {
+	switch {
+	case a.StartTime.Equal(b.StartTime):
+		return 0
+	case a.StartTime.IsZero():
+		return 1
+	case b.StartTime.IsZero():
+		return -1
+	}
+	return b.StartTime.Compare(a.StartTime)
+}
+
+func renderProtoCell(p ports.Port, _ cellContext) string {
+	// Creative protocol indicators
+	proto := strings.ToUpper(p.Protocol)
+	if p.AppProtocol != "" {
+		proto = strings.ToUpper(p.AppProtocol)
+	}
+	var protoIcon string
+	switch proto {
+	case "TCP":
+		protoIcon = "🔗"
+	case "UDP":
+		protoIcon = "📡"
+	case "HTTP":
+		protoIcon = "🌐"
+	case "HTTPS", "TLS":
+		protoIcon = "🔐"
+	case "HTTP2":
+		protoIcon = "📶"
+	case "SSH":
+		protoIcon = "🔑"
+	case "REDIS":
+		protoIcon = "🧱"
+	case "POSTGRES":
+		protoIcon = "🐘"
+	case "MYSQL":
+		protoIcon = "🐬"
+	default:
+		protoIcon = "⚡"
+	}
+	return fmt.Sprintf("%s %s", protoIcon, proto)
+}
+
+func renderPortCell(p ports.Port, _ cellContext) string {
+	// Port classification with visual indicators
+	var portClassIcon string
+	port := p.Port
+	switch {
+	case port < 1024:
+		portClassIcon = "👑" // System/privileged ports
+	case port >= 1024 && port < 49152:
+		portClassIcon = "🎪" // Registered ports
+	default:
+		portClassIcon = "🎲" // Dynamic/private ports
+	}
+	return fmt.Sprintf("%s %d", portClassIcon, p.Port)
+}
+
+// socketState returns the lower-cased state and its icon; UDP sockets without
+// a state are shown as listening.
+func socketState(p ports.Port) (string, string) {
+	// State with cyberpunk indicators
+	state := strings.ToLower(p.State)
+	switch state {
+	case "listen", "listening":
+		return state, "🎯"
+	case "established":
+		return state, "🔥"
+	case "close_wait":
+		return state, "⏳"
+	case "time_wait":
+		return state, "💭"
+	case "":
+		return "listening", "🎯"
+	default:
+		return state, "🌀"
+	}
+}
+
+func renderProcessCell(p ports.Port, ctx cellContext) string {
+	state, stateIcon := socketState(p)
+	// Process name with visual enhancement
+	process := p.Process
+	if !ctx.showState {
+		process = fmt.Sprintf("%s [%s]", process, state)
+	}
+	// Protected processes trade the state icon for a shield, and sockets
+	// with a hidden owner for a monkey.
+	switch {
+	case p.Hidden:
+		stateIcon = "🙈"
+	case p.Protection == ports.ProtectConfirm:
+		stateIcon = "🛡️"
+	case p.Protection == ports.ProtectRefuse:
+		stateIcon = "⛔"
+	}
+	return fmt.Sprintf("%s %s", stateIcon, process)
+}
+
+func renderPIDCell(p ports.Port, _ cellContext) string {
+	if p.Hidden {
+		return "💀 ?"
+	}
+	return fmt.Sprintf("💀 %d", p.PID)
+}
+
+func renderUserCell(p ports.Port, _ cellContext) string {
+	return fmt.Sprintf("👤 %s", p.User)
+}
+
+func renderAddressCell(p ports.Port, _ cellContext) string {
+	return fmt.Sprintf("🌍 %s", p.Address)
+}
+
+func renderStateCell(p ports.Port, _ cellContext) string {
+	state, stateIcon := socketState(p)
+	return fmt.Sprintf("%s %s", stateIcon, state)
+}
+
+func renderServiceCell(p ports.Port, _ cellContext) string {
+	return fmt.Sprintf("🏷️ %s", orDash(p.Service))
+}
+
+func renderUnitCell(p ports.Port, _ cellContext) string {
+	return fmt.Sprintf("⚙️ %s", unitLabel(p))
+}
+
+// unitLabel names p's systemd unit, marking user units.
+func unitLabel(p ports.Port) string {
+	switch {
+	case p.Unit == "":
+		return "-"
+	case p.UserUnit:
+		return p.Unit + " (user)"
+	}
+	return p.Unit
+}
+
+func renderContainerCell(p ports.Port, _ cellContext) string {
+	return fmt.Sprintf("🐳 %s", orDash(p.Container))
+}
+
+func renderHostCell(p ports.Port, _ cellContext) string {
+	if p.Host == "" {
+		return "💻 " + localHostName
+	}
+	return "🌐 " + p.Host
+}
+
+func renderUptimeCell(p ports.Port, ctx cellContext) string {
+	uptime := p.Uptime(ctx.now)
+	if uptime == 0 {
+		return "⏱️ -"
+	}
+	return fmt.Sprintf("⏱️ %s", formatUptime(uptime))
+}
+
+func renderCmdlineCell(p ports.Port, _ cellContext) string {
+	return fmt.Sprintf("📜 %s", orDash(p.Cmdline))
+}
+
+// formatUptime renders a compact duration such as 42s, 5m, 3h12m or 4d2h.
+func formatUptime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		days := int(d.Hours()) / 24
+		return fmt.Sprintf("%dd%dh", days, int(d.Hours())%24)
+	}
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
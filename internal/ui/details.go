@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"portkiller/internal/ports"
+	"portkiller/internal/probe"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// probeTimeout bounds a single HTTP/TLS probe.
+const probeTimeout = 2 * time.Second
+
+// probeState is the last probe outcome for a socket.
+type probeState struct {
+	result  probe.Result
+	err     error
+	pending bool
+}
+
+type probeResultMsg struct {
+	entry  ports.Port
+	result probe.Result
+	err    error
+}
+
+type openResultMsg struct {
+	url string
+	err error
+}
+
+// socketKey identifies a socket across refreshes.
+func socketKey(p ports.Port) string {
+	return fmt.Sprintf("%s/%s/%d/%d", p.Host, strings.ToLower(p.Protocol), p.PID, p.Port)
+}
+
+// isTCP reports whether entry is a TCP socket that can be probed or opened.
+func isTCP(entry ports.Port) bool {
+	return strings.HasPrefix(strings.ToLower(entry.Protocol), "tcp")
+}
+
+// annotate copies earlier probe results onto freshly listed entries. A
+// probe that is pending, failed or found nothing keeps the fingerprinted
+// protocol.
+func (m Model) annotate(entries []ports.Port) {
+	for i := range entries {
+		if state, ok := m.probes[socketKey(entries[i])]; ok {
+			if proto := state.result.Protocol(); proto != "" {
+				entries[i].AppProtocol = proto
+			}
+		}
+	}
+}
+
+func probeCmd(entry ports.Port) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		defer cancel()
+		result, err := probe.HTTP(ctx, entry.DialHost(), entry.Port)
+		return probeResultMsg{entry: entry, result: result, err: err}
+	}
+}
+
+// openURLCmd hands url to the platform opener without waiting for it.
+func openURLCmd(url string) tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", url)
+		case "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+		default:
+			cmd = exec.Command("xdg-open", url)
+		}
+		if err := cmd.Start(); err != nil {
+			return openResultMsg{url: url, err: err}
+		}
+		go cmd.Wait()
+		return openResultMsg{url: url}
+	}
+}
+
+// startProbe marks the selected socket as being probed.
+func (m *Model) startProbe(entry ports.Port) tea.Cmd {
+	if !isTCP(entry) {
+		m.toast = m.newToast("⚠️ Only TCP listeners can be probed", toastError)
+		return nil
+	}
+	if !entry.Reachable() {
+		m.toast = m.newToast(unreachableMessage(entry), toastError)
+		return nil
+	}
+	state := m.probes[socketKey(entry)]
+	if state.pending {
+		return nil
+	}
+	state.pending = true
+	m.probes[socketKey(entry)] = state
+	m.detailsVisible = true
+	m.resizeList()
+	m.toast = m.newToast(fmt.Sprintf("🔬 Probing %s:%d...", entry.DialHost(), entry.Port), toastInfo)
+	return probeCmd(entry)
+}
+
+// openEntry opens the selected listener in the browser, using https when a
+// probe found TLS.
+func (m *Model) openEntry(entry ports.Port) tea.Cmd {
+	if !isTCP(entry) {
+		m.toast = m.newToast("⚠️ Only TCP listeners can be opened", toastError)
+		return nil
+	}
+	if !entry.Reachable() {
+		m.toast = m.newToast(unreachableMessage(entry), toastError)
+		return nil
+	}
+	url := browseURL(entry)
+	if m.probes[socketKey(entry)].result.TLS {
+		url = "https" + strings.TrimPrefix(url, "http")
+	}
+	return openURLCmd(url)
+}
+
+// unreachableMessage explains why a listener in another network namespace
+// cannot be probed or opened.
+func unreachableMessage(entry ports.Port) string {
+	return fmt.Sprintf("📦 :%d is inside %s · not reachable from this machine", entry.Port, entry.Container)
+}
+
+// recordProbe stores a probe result and re-labels the matching rows.
+func (m *Model) recordProbe(msg probeResultMsg) {
+	m.probes[socketKey(msg.entry)] = probeState{result: msg.result, err: msg.err}
+
+	kind := toastSuccess
+	if msg.err != nil {
+		kind = toastError
+	}
+	m.toast = m.newToast(fmt.Sprintf("🔬 %s:%d · %s", msg.entry.DialHost(), msg.entry.Port, msg.result), kind)
+
+	m.annotate(m.entries)
+	var selected *ports.Port
+	if item, ok := m.list.SelectedItem().(portItem); ok {
+		selected = &item.entry
+	}
+	m.applyFilters()
+	if selected != nil {
+		m.selectEntry(*selected)
+	}
+}
+
+// renderDetails draws the detail pane for the selected row.
+func (m Model) renderDetails() string {
+	title := m.styles.tableSeparator.Render("━━ ") + m.styles.helpSection.Render("【 🔎 DETAILS 】") + " " +
+		m.styles.tableSeparator.Render(strings.Repeat("━", max(0, m.width-20)))
+
+	item, ok := m.list.SelectedItem().(portItem)
+	if !ok {
+		return m.fitLines(title, m.styles.dim.Render(" No target selected"), "", "")
+	}
+	entry := item.entry
+
+	summary := []string{
+		fmt.Sprintf("%s · PID %d", entry.Process, entry.PID),
+		"👤 " + orDash(entry.User),
+		fmt.Sprintf("%s %s", strings.ToUpper(entry.Protocol), hostPort(entry)),
+	}
+	if entry.Hidden {
+		summary[0] = "🙈 " + entry.Process
+	}
+	if entry.AppProtocol != "" {
+		summary[2] = fmt.Sprintf("%s/%s %s", strings.ToUpper(entry.Protocol), entry.AppProtocol, hostPort(entry))
+	}
+	switch entry.Protection {
+	case ports.ProtectConfirm:
+		summary = append(summary, "🛡️ protected by "+entry.ProtectedBy)
+	case ports.ProtectRefuse:
+		summary = append(summary, "⛔ protected by "+entry.ProtectedBy)
+	}
+	if entry.Service != "" {
+		summary = append(summary, "🏷️ "+entry.Service)
+	}
+	if entry.Unit != "" {
+		summary = append(summary, "⚙️ "+unitLabel(entry))
+	}
+	if entry.Host != "" {
+		summary = append(summary, "🌐 "+entry.Host)
+	}
+	if uptime := entry.Uptime(time.Now()); uptime > 0 {
+		summary = append(summary, "⏱️ "+formatUptime(uptime))
+	}
+	if entry.State != "" {
+		summary = append(summary, strings.ToLower(entry.State))
+	}
+
+	var probeLine string
+	state, probed := m.probes[socketKey(entry)]
+	switch {
+	case !isTCP(entry):
+		probeLine = m.styles.dim.Render("🔬 not probeable (UDP)")
+	case !entry.Reachable():
+		probeLine = m.styles.dim.Render("🔬 not probeable (inside " + entry.Container + ")")
+	case state.pending:
+		probeLine = m.styles.modalStatus.Render("🔬 probing...")
+	case !probed:
+		probeLine = m.styles.dim.Render(fmt.Sprintf("🔬 %s to probe · %s to open %s",
+			primaryKey(m.keys.Probe), primaryKey(m.keys.Open), browseURL(entry)))
+	case state.err != nil:
+		probeLine = m.styles.errorText.Render("🔬 " + state.result.String())
+	default:
+		probeLine = m.styles.successToast.Render("🔬 " + state.result.String())
+	}
+
+	return m.fitLines(
+		title,
+		m.styles.inputText.Render(" "+strings.Join(summary, " · ")),
+		m.styles.status.Render(" 📜 "+orDash(entry.Cmdline)),
+		" "+probeLine,
+	)
+}
+
+// fitLines truncates each line to the terminal width and joins them.
+func (m Model) fitLines(lines ...string) string {
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, m.width, "…")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"portkiller/internal/config"
+	"portkiller/internal/history"
+	"portkiller/internal/ports"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// historyLimit caps how many kills the history view loads.
+	historyLimit = 50
+	// historyRows is how many kills the history view shows at once.
+	historyRows = 8
+)
+
+// historyView is the open kill history and its selected entry.
+type historyView struct {
+	entries []history.Entry
+	cursor  int
+}
+
+type historyLoadedMsg struct {
+	entries []history.Entry
+	err     error
+}
+
+type relaunchResultMsg struct {
+	entry history.Entry
+	pid   int
+	log   string
+	err   error
+}
+
+func loadHistoryCmd(cfg config.Config) tea.Cmd {
+	return func() tea.Msg {
+		path, err := cfg.HistoryPath()
+		if err != nil {
+			return historyLoadedMsg{err: err}
+		}
+		entries, err := history.Load(path, historyLimit)
+		return historyLoadedMsg{entries: entries, err: err}
+	}
+}
+
+// relaunchCmd starts a killed command again in its original directory. Its
+// output goes to a log file next to the history file.
+func relaunchCmd(entry history.Entry, cfg config.Config) tea.Cmd {
+	return func() tea.Msg {
+		logPath := relaunchLogPath(cfg, entry.Process)
+		pid, err := ports.Launch(ports.ProcessSnapshot{Argv: entry.Argv, Cwd: entry.Cwd, Env: entry.Env}, logPath)
+		return relaunchResultMsg{entry: entry, pid: pid, log: logPath, err: err}
+	}
+}
+
+// relaunchLogPath is where a relaunched process's output goes: a
+// timestamped file in the relaunch directory next to the history file, or
+// nowhere when that cannot be located.
+func relaunchLogPath(cfg config.Config, process string) string {
+	path, err := cfg.HistoryPath()
+	if err != nil {
+		return ""
+	}
+	name := fmt.Sprintf("%s-%s.log", filepath.Base(process), time.Now().Format("20060102-150405"))
+	return filepath.Join(filepath.Dir(path), "relaunch", name)
+}
+
+// openHistory shows the kill history loaded by loadHistoryCmd.
+func (m *Model) openHistory(msg historyLoadedMsg) {
+	if msg.err != nil {
+		m.toast = m.newToast("⚠️ Could not read kill history", toastError)
+		m.errMsg = fmt.Sprintf("history: %v", msg.err)
+		return
+	}
+	if len(msg.entries) == 0 {
+		m.toast = m.newToast("🕘 No kills recorded yet", toastInfo)
+		return
+	}
+	m.history = &historyView{entries: msg.entries}
+	m.resizeList()
+}
+
+// updateHistory handles a key press while the history view is open.
+func (m Model) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	view := m.history
+	switch {
+	case key.Matches(msg, m.keys.Back, m.keys.History):
+		m.history = nil
+		m.resizeList()
+	case key.Matches(msg, m.keys.Up):
+		view.cursor = max(0, view.cursor-1)
+	case key.Matches(msg, m.keys.Down):
+		view.cursor = min(len(view.entries)-1, view.cursor+1)
+	case key.Matches(msg, m.keys.Relaunch):
+		entry := view.entries[view.cursor]
+		if !entry.Relaunchable() {
+			m.toast = m.newToast(fmt.Sprintf("⚠️ No command recorded for %s", entry.Process), toastError)
+			return m, nil
+		}
+		m.history = nil
+		m.resizeList()
+		m.toast = m.newToast(fmt.Sprintf("♻️ Relaunching %s...", entry.Process), toastInfo)
+		return m, relaunchCmd(entry, m.config)
+	}
+	return m, nil
+}
+
+// recordRelaunch reports a relaunch and reloads the list so the new
+// listener shows up.
+func (m *Model) recordRelaunch(msg relaunchResultMsg) tea.Cmd {
+	if msg.err != nil {
+		m.toast = m.newToast(fmt.Sprintf("⚠️ Failed to relaunch %s", msg.entry.Process), toastError)
+		m.errMsg = fmt.Sprintf("relaunch failed: %v", msg.err)
+		return nil
+	}
+	m.toast = m.newToast(fmt.Sprintf("♻️ Relaunched %s as PID %d in %s", msg.entry.Process, msg.pid, displayPath(msg.entry.Cwd)), toastSuccess)
+	if msg.log != "" {
+		m.statusMsg = "📜 Output: " + displayPath(msg.log)
+	}
+	return m.loadPortsCmd()
+}
+
+// displayPath shortens paths under the home directory to ~.
+func displayPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		if rest, ok := strings.CutPrefix(path, home); ok && (rest == "" || strings.HasPrefix(rest, string(filepath.Separator))) {
+			return "~" + rest
+		}
+	}
+	return path
+}
+
+func renderHistory(view historyView, keys keyMap, st styles, width int) string {
+	title := st.modalTitle.Render(fmt.Sprintf("🕘 KILL HISTORY 【 %d recorded 】", len(view.entries)))
+	rowWidth := max(40, min(96, width-12))
+
+	start := min(max(0, view.cursor-historyRows/2), max(0, len(view.entries)-historyRows))
+	end := min(len(view.entries), start+historyRows)
+
+	rows := []string{title, ""}
+	for i := start; i < end; i++ {
+		entry := view.entries[i]
+		result := "✅"
+		if entry.Result != history.ResultTerminated {
+			result = "❌"
+		}
+		line := padded(fmt.Sprintf(" %s %s  :%-5d %-16s PID %-7d %s",
+			result, entry.Time.Local().Format("Jan 02 15:04"), entry.Port, entry.Process, entry.PID, displayPath(entry.Cwd)), rowWidth)
+		if i == view.cursor {
+			rows = append(rows, st.selectedTitle.Render(line))
+		} else {
+			rows = append(rows, st.helpDesc.Render(line))
+		}
+	}
+
+	selected := view.entries[view.cursor]
+	command := strings.Join(selected.Argv, " ")
+	if command == "" {
+		command = orDash(selected.Cmdline)
+	}
+	rows = append(rows, "", st.status.Render(padded("📜 "+command, rowWidth)))
+	if selected.Error != "" {
+		rows = append(rows, st.errorText.Render(padded("⚠️ "+selected.Error, rowWidth)))
+	}
+
+	rows = append(rows, "", st.modalSubtitle.Render(fmt.Sprintf("%s  move · %s  relaunch in original cwd · %s  close",
+		bindingKeys([]key.Binding{keys.Down, keys.Up}), primaryKey(keys.Relaunch), primaryKey(keys.Back))))
+
+	content := st.modalContent.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+	return st.modal.Render(content)
+}
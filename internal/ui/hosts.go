@@ -0,0 +1,326 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"portkiller/internal/ports"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Host is a machine shown in its own tab of the host switcher.
+type Host struct {
+	// Name labels the tab and matches ports.Port.Host of its entries.
+	Name     string
+	Provider ports.Provider
+	// RefreshInterval reloads the host's ports periodically; zero disables
+	// it.
+	RefreshInterval time.Duration
+	// ListTimeout bounds a single List call.
+	ListTimeout time.Duration
+}
+
+// hostTab is a host with the outcome of its last listing. Each tab loads,
+// fails and refreshes on its own, so a slow or unreachable host does not
+// hold up the others.
+type hostTab struct {
+	Host
+	entries []ports.Port
+	err     error
+	loaded  bool
+	// listing is set while a List call for the host is outstanding, so
+	// refresh ticks do not stack up behind a slow host.
+	listing bool
+}
+
+// localHostName labels this machine's tab and its rows in the host column.
+const localHostName = "localhost"
+
+const (
+	hostTabLines     = 1
+	hostTabSeparator = " │ "
+)
+
+// WithHosts returns the model with a tab for each of hosts after this
+// machine's, and a last tab merging all of them.
+func (m Model) WithHosts(hosts []Host) Model {
+	tabs := append([]hostTab(nil), m.tabs...)
+	for _, h := range hosts {
+		tabs = append(tabs, hostTab{Host: h})
+	}
+	m.tabs = tabs
+	return m
+}
+
+// multiHost reports whether the host switcher is shown.
+func (m Model) multiHost() bool {
+	return len(m.tabs) > 1
+}
+
+// allHosts reports whether the merged tab is shown.
+func (m Model) allHosts() bool {
+	return m.tab == len(m.tabs)
+}
+
+// shownTabs returns the indexes of the hosts whose ports are listed.
+func (m Model) shownTabs() []int {
+	if !m.allHosts() {
+		return []int{m.tab}
+	}
+	shown := make([]int, len(m.tabs))
+	for i := range shown {
+		shown[i] = i
+	}
+	return shown
+}
+
+func (m Model) showsTab(i int) bool {
+	return m.allHosts() || m.tab == i
+}
+
+// tabName is the label of the host at index i.
+func (m Model) tabName(i int) string {
+	if name := m.tabs[i].Name; name != "" {
+		return name
+	}
+	return localHostName
+}
+
+// tabOf returns the index of the host entry was listed on, or -1.
+func (m Model) tabOf(entry ports.Port) int {
+	for i, tab := range m.tabs {
+		if tab.Name == entry.Host {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadPortsCmd reloads the hosts whose ports are listed.
+func (m *Model) loadPortsCmd() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, i := range m.shownTabs() {
+		cmds = append(cmds, m.loadHostCmd(i))
+	}
+	return tea.Batch(cmds...)
+}
+
+// loadHostCmd lists the host at index i and marks it as being listed
+// until the result arrives.
+func (m *Model) loadHostCmd(i int) tea.Cmd {
+	m.tabs[i].listing = true
+	p, timeout := m.tabs[i].Provider, m.tabs[i].ListTimeout
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		entries, err := p.List(ctx)
+		return portsLoadedMsg{host: i, entries: entries, err: err}
+	}
+}
+
+// refreshTickCmd schedules the next periodic reload of the host at index
+// i, if one is configured.
+func (m Model) refreshTickCmd(i int) tea.Cmd {
+	interval := m.tabs[i].RefreshInterval
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return refreshTickMsg{host: i}
+	})
+}
+
+// loadAllCmd loads every host and starts their refresh timers.
+func (m *Model) loadAllCmd() tea.Cmd {
+	cmds := make([]tea.Cmd, 0, 2*len(m.tabs))
+	for i := range m.tabs {
+		cmds = append(cmds, m.loadHostCmd(i), m.refreshTickCmd(i))
+	}
+	return tea.Batch(cmds...)
+}
+
+// recordListing stores a host's listing and, if the host is shown, lists
+// it. A failed listing keeps the host's previous ports.
+func (m *Model) recordListing(msg portsLoadedMsg) {
+	tab := &m.tabs[msg.host]
+	tab.listing = false
+	if msg.err != nil {
+		tab.err = msg.err
+		if m.showsTab(msg.host) {
+			m.errMsg = m.loadError(msg.host)
+			m.statusMsg = ""
+		}
+		return
+	}
+
+	// Rows are matched to their tab by host name, whatever the provider.
+	for i := range msg.entries {
+		msg.entries[i].Host = tab.Name
+	}
+	m.annotate(msg.entries)
+	tab.entries, tab.err, tab.loaded = msg.entries, nil, true
+	if m.showsTab(msg.host) {
+		m.showTab()
+	}
+}
+
+func (m Model) loadError(i int) string {
+	if !m.multiHost() {
+		return fmt.Sprintf("error loading ports: %v", m.tabs[i].err)
+	}
+	return fmt.Sprintf("error loading ports from %s: %v", m.tabName(i), m.tabs[i].err)
+}
+
+// showTab lists the ports of the shown hosts and reports how they loaded.
+func (m *Model) showTab() {
+	m.entries = nil
+	loaded := false
+	var errs []string
+	for _, i := range m.shownTabs() {
+		tab := m.tabs[i]
+		m.entries = append(m.entries, tab.entries...)
+		loaded = loaded || tab.loaded
+		if tab.err != nil {
+			errs = append(errs, m.loadError(i))
+		}
+	}
+	shown := m.applyFilters()
+	m.errMsg = strings.Join(errs, " · ")
+	if !loaded {
+		m.statusMsg = ""
+		if len(errs) == 0 {
+			m.statusMsg = "🔍 Loading active ports..."
+		}
+		return
+	}
+
+	m.statusMsg = fmt.Sprintf("✨ Loaded %d ports @ %s", len(m.entries), time.Now().Format(time.Kitchen))
+	if !m.query.Empty() || m.toggles.Any() {
+		m.statusMsg += fmt.Sprintf(" · %d shown", shown)
+	}
+	if hidden := ports.HiddenCount(m.entries); hidden > 0 {
+		m.statusMsg += fmt.Sprintf(" · %s owned by unknown PIDs (run as root to see them)", strings.ToLower(pluralSockets(hidden)))
+	}
+}
+
+// forgetEntry drops a killed entry from its host's listing until the next
+// reload.
+func (m *Model) forgetEntry(entry ports.Port) {
+	i := m.tabOf(entry)
+	if i < 0 {
+		return
+	}
+	entries := m.tabs[i].entries
+	for j, e := range entries {
+		if sameSocket(e, entry) {
+			m.tabs[i].entries = append(entries[:j:j], entries[j+1:]...)
+			return
+		}
+	}
+}
+
+// selectTab switches the host switcher to the tab at index i, where
+// len(m.tabs) is the merged view. Ports are shown from the last listing;
+// each host keeps its own refresh cadence.
+func (m *Model) selectTab(i int) {
+	if !m.multiHost() || i < 0 || i > len(m.tabs) || i == m.tab {
+		return
+	}
+	m.tab = i
+	m.layout = newColumnLayout(m.viewColumns(), m.config.ColumnWidths)
+	if m.sortColumn != "" && !containsColumn(m.viewColumns(), m.sortColumn) {
+		m.sortColumn, m.sortDesc = "", false
+	}
+	m.resizeList()
+	m.list.ResetSelected()
+	m.showTab()
+}
+
+// cycleTab moves delta tabs along the host switcher, wrapping around.
+func (m *Model) cycleTab(delta int) {
+	count := len(m.tabs) + 1
+	m.selectTab(((m.tab+delta)%count + count) % count)
+}
+
+// viewColumns are the configured columns, plus the host column in the
+// merged view so rows can be told apart.
+func (m Model) viewColumns() []string {
+	columns := m.config.Columns
+	if m.allHosts() && !containsColumn(columns, "host") {
+		columns = append([]string{"host"}, columns...)
+	}
+	return columns
+}
+
+func containsColumn(columns []string, name string) bool {
+	for _, column := range columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hostTabLabels returns the caption of every tab, the merged view last.
+func (m Model) hostTabLabels() []string {
+	labels := make([]string, 0, len(m.tabs)+1)
+	total, failed := 0, false
+	for i, tab := range m.tabs {
+		icon, badge := "🌐", strconv.Itoa(len(tab.entries))
+		if tab.Name == "" {
+			icon = "💻"
+		}
+		switch {
+		case tab.err != nil:
+			badge = "⚠️"
+		case !tab.loaded:
+			badge = "…"
+		}
+		labels = append(labels, fmt.Sprintf(" %s %s %s ", icon, m.tabName(i), badge))
+		total += len(tab.entries)
+		failed = failed || tab.err != nil
+	}
+	merged := fmt.Sprintf(" 🌍 all hosts %d ", total)
+	if failed {
+		merged = fmt.Sprintf(" 🌍 all hosts %d ⚠️ ", total)
+	}
+	return append(labels, merged)
+}
+
+// renderHostTabs draws the host switcher as the last header line.
+func (m Model) renderHostTabs() string {
+	labels := m.hostTabLabels()
+	rendered := make([]string, len(labels))
+	for i, label := range labels {
+		style := m.styles.hostTab
+		if i == m.tab {
+			style = m.styles.hostTabActive.Background(lipgloss.Color(m.accentColor(0)))
+		}
+		rendered[i] = style.Render(label)
+	}
+	bar := strings.Join(rendered, m.styles.tableSeparator.Render(hostTabSeparator))
+	if keys := bindingKeys([]key.Binding{m.keys.NextHost, m.keys.PrevHost}); keys != "" {
+		bar += m.styles.hint.Render("   ⇥ " + keys)
+	}
+	return padded(bar, m.width)
+}
+
+// hostTabAt returns the index of the tab drawn at screen column x, or -1.
+func (m Model) hostTabAt(x int) int {
+	start := 0
+	for i, label := range m.hostTabLabels() {
+		width := lipgloss.Width(label)
+		if x >= start && x < start+width {
+			return i
+		}
+		start += width + lipgloss.Width(hostTabSeparator)
+	}
+	return -1
+}
@@ -0,0 +1,230 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds every binding the TUI reacts to. It is built from the
+// configured action → keys table so the footer and help card always show the
+// keys that are actually live.
+type keyMap struct {
+	Up             key.Binding
+	Down           key.Binding
+	PageUp         key.Binding
+	PageDown       key.Binding
+	Top            key.Binding
+	Bottom         key.Binding
+	Kill           key.Binding
+	Restart        key.Binding
+	Refresh        key.Binding
+	Filter         key.Binding
+	Query          key.Binding
+	ToggleMine     key.Binding
+	ToggleSystem   key.Binding
+	ToggleLoopback key.Binding
+	ToggleUDP      key.Binding
+	Back           key.Binding
+	Help           key.Binding
+	Quit           key.Binding
+	Confirm        key.Binding
+	Cancel         key.Binding
+	Sudo           key.Binding
+	StopUnit       key.Binding
+	RestartUnit    key.Binding
+	Yank           key.Binding
+	Open           key.Binding
+	Probe          key.Binding
+	Details        key.Binding
+	YankPID        key.Binding
+	YankPort       key.Binding
+	YankAddress    key.Binding
+	YankURL        key.Binding
+	YankCmdline    key.Binding
+	YankKill       key.Binding
+	History        key.Binding
+	Relaunch       key.Binding
+	NextHost       key.Binding
+	PrevHost       key.Binding
+}
+
+func newKeyMap(actions map[string][]string) keyMap {
+	bind := func(action, desc string) key.Binding {
+		keys := actions[action]
+		return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyLabel(keys), desc))
+	}
+
+	return keyMap{
+		Up:             bind("up", "Move up"),
+		Down:           bind("down", "Move down"),
+		PageUp:         bind("page_up", "Previous page"),
+		PageDown:       bind("page_down", "Next page"),
+		Top:            bind("top", "Jump to first target"),
+		Bottom:         bind("bottom", "Jump to last target"),
+		Kill:           bind("kill", "Execute termination protocol"),
+		Restart:        bind("restart", "Restart the process on the same port"),
+		Refresh:        bind("refresh", "Reload target matrix"),
+		Filter:         bind("filter", "Initiate search protocol"),
+		Query:          bind("query", "Structured query (port:3000-3999)"),
+		ToggleMine:     bind("toggle_mine", "Only show my user's ports"),
+		ToggleSystem:   bind("toggle_system", "Hide privileged ports < 1024"),
+		ToggleLoopback: bind("toggle_loopback", "Hide loopback-only listeners"),
+		ToggleUDP:      bind("toggle_udp", "Hide UDP sockets"),
+		Back:           bind("back", "Exit search mode"),
+		Help:           bind("help", "Toggle command matrix"),
+		Quit:           bind("quit", "Exit system"),
+		Confirm:        bind("confirm", "Confirm elimination"),
+		Cancel:         bind("cancel", "Abort current operation"),
+		Sudo:           bind("sudo", "Retry a denied kill with sudo"),
+		StopUnit:       bind("stop_unit", "Stop the owning systemd unit"),
+		RestartUnit:    bind("restart_unit", "Restart the owning systemd unit"),
+		Yank:           bind("yank", "Copy PID, port, URL or kill command"),
+		Open:           bind("open", "Open in browser"),
+		Probe:          bind("probe", "Probe for HTTP/TLS"),
+		Details:        bind("details", "Toggle detail pane"),
+		YankPID:        bind("yank_pid", "PID"),
+		YankPort:       bind("yank_port", "Port"),
+		YankAddress:    bind("yank_address", "host:port"),
+		YankURL:        bind("yank_url", "URL"),
+		YankCmdline:    bind("yank_cmdline", "Command line"),
+		YankKill:       bind("yank_kill", "Kill command"),
+		History:        bind("history", "Kill history and relaunch"),
+		Relaunch:       bind("relaunch", "Relaunch killed command"),
+		NextHost:       bind("next_host", "Next host tab"),
+		PrevHost:       bind("prev_host", "Previous host tab"),
+	}
+}
+
+// footerHint is one entry of the command hint line.
+type footerHint struct {
+	icon     string
+	label    string
+	bindings []key.Binding
+}
+
+func (k keyMap) footerHints() []footerHint {
+	return []footerHint{
+		{"🎯", "NAVIGATE", []key.Binding{k.Down, k.Up}},
+		{"💀", "TERMINATE", []key.Binding{k.Kill}},
+		{"🔄", "REFRESH", []key.Binding{k.Refresh}},
+		{"🔍", "SCAN", []key.Binding{k.Filter}},
+		{"📋", "YANK", []key.Binding{k.Yank}},
+		{"⌘", "QUERY", []key.Binding{k.Query}},
+		{"🎛️", "TOGGLES", []key.Binding{k.ToggleMine, k.ToggleSystem, k.ToggleLoopback, k.ToggleUDP}},
+		{"❓", "INFO", []key.Binding{k.Help}},
+		{"💨", "ESCAPE", []key.Binding{k.Quit}},
+	}
+}
+
+// helpSection groups rows on the help card under a heading.
+type helpSection struct {
+	title string
+	rows  []helpRow
+}
+
+type helpRow struct {
+	name     string
+	bindings []key.Binding
+	// desc overrides the first binding's description when rows combine keys.
+	desc string
+}
+
+func (k keyMap) helpSections() []helpSection {
+	return []helpSection{
+		{"【 NAVIGATION PROTOCOLS 】", []helpRow{
+			{"🎯 Movement", []key.Binding{k.Down, k.Up}, "Navigate through targets"},
+			{"⏪ Page up", []key.Binding{k.PageUp}, ""},
+			{"⏩ Page down", []key.Binding{k.PageDown}, ""},
+			{"⏫ Top", []key.Binding{k.Top}, ""},
+			{"⏬ Bottom", []key.Binding{k.Bottom}, ""},
+		}},
+		{"【 COMBAT OPERATIONS 】", []helpRow{
+			{"💀 Terminate", []key.Binding{k.Kill}, ""},
+			{"🔁 Restart", []key.Binding{k.Restart}, ""},
+			{"⚔️  Confirm", []key.Binding{k.Confirm}, ""},
+			{"🛡️  Abort", []key.Binding{k.Cancel}, ""},
+			{"🔓 Sudo retry", []key.Binding{k.Sudo}, ""},
+			{"⏹️  Stop unit", []key.Binding{k.StopUnit}, ""},
+			{"🔁 Restart unit", []key.Binding{k.RestartUnit}, ""},
+			{"🕘 History", []key.Binding{k.History}, ""},
+		}},
+		{"【 SYSTEM OPERATIONS 】", []helpRow{
+			{"🔄 Refresh", []key.Binding{k.Refresh}, ""},
+			{"🔍 Scan", []key.Binding{k.Filter}, ""},
+			{"⌘ Query", []key.Binding{k.Query}, ""},
+			{"📋 Yank", []key.Binding{k.Yank}, ""},
+			{"🌐 Open", []key.Binding{k.Open}, ""},
+			{"🔬 Probe", []key.Binding{k.Probe}, ""},
+			{"🔎 Details", []key.Binding{k.Details}, ""},
+			{"🌍 Hosts", []key.Binding{k.NextHost, k.PrevHost}, "Switch host tab"},
+			{"👤 Mine", []key.Binding{k.ToggleMine}, ""},
+			{"👑 System", []key.Binding{k.ToggleSystem}, ""},
+			{"🔁 Loopback", []key.Binding{k.ToggleLoopback}, ""},
+			{"📡 UDP", []key.Binding{k.ToggleUDP}, ""},
+			{"💨 Escape", []key.Binding{k.Back}, ""},
+			{"❓ Info", []key.Binding{k.Help}, ""},
+			{"💨 Logout", []key.Binding{k.Quit}, ""},
+		}},
+	}
+}
+
+// helpRowCount is the number of lines the help card body occupies.
+func (k keyMap) helpRowCount() int {
+	sections := k.helpSections()
+	rows := len(sections)*2 - 1
+	for _, section := range sections {
+		rows += len(section.rows)
+	}
+	return rows
+}
+
+// bindingKeys joins the help keys of several bindings, e.g. "j/↓/k/↑".
+func bindingKeys(bindings []key.Binding) string {
+	parts := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		if !b.Enabled() {
+			continue
+		}
+		parts = append(parts, b.Help().Key)
+	}
+	return strings.Join(parts, "/")
+}
+
+// primaryKey is the first key of a binding, used for button captions.
+func primaryKey(b key.Binding) string {
+	keys := b.Keys()
+	if len(keys) == 0 {
+		return ""
+	}
+	return keyLabel(keys[:1])
+}
+
+func (r helpRow) description() string {
+	if r.desc != "" || len(r.bindings) == 0 {
+		return r.desc
+	}
+	return r.bindings[0].Help().Desc
+}
+
+var keyGlyphs = map[string]string{
+	"up":     "↑",
+	"down":   "↓",
+	"left":   "←",
+	"right":  "→",
+	"pgup":   "PgUp",
+	"pgdown": "PgDn",
+	" ":      "space",
+}
+
+// keyLabel renders a key list for humans, e.g. ["k", "up"] → "k/↑".
+func keyLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		if glyph, ok := keyGlyphs[k]; ok {
+			k = glyph
+		}
+		labels[i] = k
+	}
+	return strings.Join(labels, "/")
+}
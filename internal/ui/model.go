@@ -1,14 +1,21 @@
 package ui
 
 import (
-	"context"
+	"errors"
 	"fmt"
+	"os/user"
 	"strings"
 	"time"
 
+	"portkiller/internal/config"
+	"portkiller/internal/killer"
 	"portkiller/internal/ports"
+	"portkiller/internal/prefs"
+	"portkiller/internal/query"
 
+	"github.com/charmbracelet/bubbles/key"
 	list "github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
@@ -16,27 +23,63 @@ import (
 
 // Model implements the Bubble Tea program for pzapp.
 type Model struct {
-	provider ports.Provider
+	config config.Config
+	killer *killer.Killer
+	// sudoKill is the pzapp command run under sudo when a kill is denied.
+	sudoKill []string
+	// tabs are the machines of the host switcher, this one first. tab is
+	// the one shown, or len(tabs) for the merged view of all of them.
+	tabs []hostTab
+	tab  int
 
 	list      list.Model
+	keys      keyMap
+	theme     Theme
+	styles    styles
+	entries   []ports.Port
 	statusMsg string
 	errMsg    string
 	width     int
 	height    int
 	ready     bool
 
-	confirm     *ports.Port
-	killPending bool
+	confirm      *ports.Port
+	killPending  bool
+	killDenied   bool
+	killRestart  bool
+	confirmInput textinput.Model
+	yank        *ports.Port
+	history     *historyView
+	// clipboard is the OSC52 sequence sent ahead of the next frames, so it
+	// reaches the terminal through the renderer; clipboardID tells a stale
+	// clear apart from one for the latest copy.
+	clipboard   string
+	clipboardID int
+
+	query        query.Query
+	queryInput   textinput.Model
+	queryEditing bool
+	queryErr     string
+	toggles      query.Toggles
+	currentUser  string
 
 	toast        toastState
-	columns      columnWidths
+	layout       *columnLayout
+	sortColumn   string
+	sortDesc     bool
+
+	probes         map[string]probeState
+	detailsVisible bool
 	helpVisible  bool
+	reduceMotion bool
 	accentIndex  int
 	taglineIndex int
 	tickCount    int
 }
 
 type portsLoadedMsg struct {
+	// host is the index of the tab that was listed.
+	host    int
 	entries []ports.Port
 	err     error
 }
@@ -44,12 +87,23 @@ type portsLoadedMsg struct {
 type killResultMsg struct {
 	entry ports.Port
 	err   error
+	// historyErr and auditErr are set when the kill could not be recorded.
+	historyErr error
+	auditErr   error
+}
+
+type prefsSavedMsg struct {
+	err error
 }
 
 type tickMsg struct {
 	when time.Time
 }
 
+type refreshTickMsg struct {
+	host int
+}
+
 type toastKind int
 
 const (
@@ -65,39 +119,32 @@ type toastState struct {
 	expires time.Time
 }
 
-type columnWidths struct {
-	proto   int
-	port    int
-	process int
-	pid     int
-	user    int
-	address int
-}
-
-const columnSeparator = " | "
-
-func defaultColumns() columnWidths {
-	return columnWidths{
-		proto:   4,
-		port:    5,
-		process: 16,
-		pid:     6,
-		user:    9,
-		address: 28,
-	}
-}
-
 // New creates the root Bubble Tea model.
-func New(provider ports.Provider) Model {
+func New(provider ports.Provider, cfg config.Config) Model {
+	theme := resolveTheme(cfg.Theme, cfg.Themes)
+	st := newStyles(theme)
+
 	baseDelegate := list.NewDefaultDelegate()
 	baseDelegate.ShowDescription = false
 	baseDelegate.SetSpacing(0)
-	baseDelegate.Styles.NormalTitle = listTitleStyle
-	baseDelegate.Styles.SelectedTitle = selectedTitleBase.Background(lipgloss.Color(matrixAccentNeon))
-	baseDelegate.Styles.NormalDesc = listDescStyle
-	baseDelegate.Styles.SelectedDesc = selectedDescBase.Background(lipgloss.Color(matrixAccentPink))
-
-	model := Model{provider: provider}
+	baseDelegate.Styles.NormalTitle = st.listTitle
+	baseDelegate.Styles.SelectedTitle = st.selectedTitle
+	baseDelegate.Styles.NormalDesc = st.listDesc
+	baseDelegate.Styles.SelectedDesc = st.selectedDesc
+
+	model := Model{
+		config:       cfg,
+		tabs: []hostTab{{Host: Host{
+			Provider:        provider,
+			RefreshInterval: cfg.RefreshInterval,
+			ListTimeout:     cfg.ListTimeout,
+		}}},
+		keys:         newKeyMap(cfg.Keys),
+		theme:        theme,
+		styles:       st,
+		reduceMotion: cfg.ReduceMotion,
+		probes:       map[string]probeState{},
+	}
 
 	l := list.New([]list.Item{}, baseDelegate, 0, 0)
 	l.Title = ""
@@ -106,23 +153,67 @@ func New(provider ports.Provider) Model {
 	l.SetShowStatusBar(false)
 	l.SetShowHelp(false)
 	l.SetFilteringEnabled(true)
-	l.Styles.HelpStyle = helpStyle
-	l.Styles.FilterCursor = filterCursorBase.Foreground(lipgloss.Color(matrixAccentNeon))
-	l.Styles.FilterPrompt = filterPromptStyle
-	l.Styles.TitleBar = filterBarStyle
+	l.Styles.HelpStyle = st.help
+	l.Styles.FilterCursor = st.filterCursor
+	l.Styles.FilterPrompt = st.filterPrompt
+	l.Styles.TitleBar = st.filterBar
+	l.KeyMap.CursorUp = model.keys.Up
+	l.KeyMap.CursorDown = model.keys.Down
+	l.KeyMap.PrevPage = model.keys.PageUp
+	l.KeyMap.NextPage = model.keys.PageDown
+	l.KeyMap.GoToStart = model.keys.Top
+	l.KeyMap.GoToEnd = model.keys.Bottom
+	l.KeyMap.Filter = model.keys.Filter
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+	l.DisableQuitKeybindings()
 
 	// Update the pre-created model with list
 	model.list = l
+	model.queryInput = newQueryInput(st)
+	model.confirmInput = newConfirmInput(st)
 	model.statusMsg = "🔍 Loading active ports..."
-	model.columns = defaultColumns()
+	if current, err := user.Current(); err == nil {
+		model.currentUser = current.Username
+	}
+	if k, err := killer.New(cfg, nil); err != nil {
+		model.errMsg = fmt.Sprintf("kill history: %v", err)
+		model.killer = &killer.Killer{Policy: cfg.Kill.Policy()}
+	} else {
+		model.killer = k
+	}
+	model.toggles = cfg.Filters.Toggles()
+	if saved, err := prefs.Load(); err != nil {
+		model.errMsg = fmt.Sprintf("loading prefs: %v", err)
+	} else if saved.Toggles != nil {
+		model.toggles = *saved.Toggles
+	}
+	if q, err := query.Parse(cfg.Filters.Query); err == nil {
+		model.query = q
+	}
+	model.layout = newColumnLayout(cfg.Columns, cfg.ColumnWidths)
 	model.applyAccentStyles()
 
 	return model
 }
 
+// WithKiller returns the model using k for kills, so they are audited the
+// same way as CLI kills.
+func (m Model) WithKiller(k *killer.Killer) Model {
+	m.killer = k
+	return m
+}
+
+// WithHost returns the model labelled as inspecting the remote host name.
+func (m Model) WithHost(name string) Model {
+	m.tabs = append([]hostTab(nil), m.tabs...)
+	m.tabs[0].Name = name
+	return m
+}
+
 // Init starts the asynchronous refresh when the program boots.
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(tea.EnterAltScreen, loadPortsCmd(m.provider), animationTickCmd())
+	return tea.Batch(tea.EnterAltScreen, m.loadAllCmd(), m.animationTickCmd())
 }
 
 // Update applies incoming Bubble Tea messages to the model state.
@@ -138,76 +229,153 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case portsLoadedMsg:
+		m.recordListing(msg)
+		return m, nil
+
+	case prefsSavedMsg:
 		if msg.err != nil {
-			m.errMsg = fmt.Sprintf("error loading ports: %v", msg.err)
-			m.statusMsg = ""
-			return m, nil
+			m.toast = m.newToast("⚠️ Could not save toggles", toastError)
+			m.errMsg = fmt.Sprintf("saving prefs: %v", msg.err)
 		}
+		return m, nil
+
+	case clipboardMsg:
+		m.toast = m.newToast(fmt.Sprintf("📋 Copied %s: %s", msg.label, msg.value), toastSuccess)
+		return m, nil
+
+	case clipboardSentMsg:
+		if msg.id == m.clipboardID {
+			m.clipboard = ""
+		}
+		return m, nil
+
+	case probeResultMsg:
+		m.recordProbe(msg)
+		return m, nil
 
-		items := make([]list.Item, 0, len(msg.entries))
-		for _, entry := range msg.entries {
-			items = append(items, portItem{entry: entry, layout: &m.columns})
+	case openResultMsg:
+		if msg.err != nil {
+			m.toast = m.newToast("⚠️ Could not open "+msg.url, toastError)
+			m.errMsg = fmt.Sprintf("open browser: %v", msg.err)
+		} else {
+			m.toast = m.newToast("🌐 Opened "+msg.url, toastSuccess)
 		}
-		m.list.SetItems(items)
-		m.errMsg = ""
-		m.recalcColumns()
-		m.statusMsg = fmt.Sprintf("✨ Loaded %d ports @ %s", len(items), time.Now().Format(time.Kitchen))
 		return m, nil
 
+	case historyLoadedMsg:
+		m.openHistory(msg)
+		return m, nil
+
+	case relaunchResultMsg:
+		return m, m.recordRelaunch(msg)
+
+	case sudoKillResultMsg:
+		return m, m.recordSudoKill(msg)
+
+	case unitResultMsg:
+		return m, m.recordUnitResult(msg)
+
+	case restartStoppedMsg, restartLaunchedMsg, restartDoneMsg:
+		return m, m.updateRestart(msg)
+
 	case killResultMsg:
 		m.killPending = false
 		m.confirm = nil
+		var permErr *ports.PermissionError
+		if errors.As(msg.err, &permErr) && len(m.sudoKill) > 0 && msg.entry.Host == "" {
+			// Keep the modal up so the kill can be retried with sudo.
+			entry := msg.entry
+			m.confirm = &entry
+			m.killDenied = true
+			m.statusMsg = fmt.Sprintf("🔒 Permission denied · press %s to retry with sudo", primaryKey(m.keys.Sudo))
+		}
 		m.resizeList()
+		if msg.historyErr != nil {
+			m.errMsg = fmt.Sprintf("recording kill history: %v", msg.historyErr)
+		}
+		if msg.auditErr != nil {
+			m.errMsg = fmt.Sprintf("writing audit log: %v", msg.auditErr)
+		}
 		if msg.err != nil {
-			m.toast = newToast(fmt.Sprintf("⚠️ Failed to terminate %s (%d)", msg.entry.Process, msg.entry.PID), toastError)
+			m.toast = m.newToast(fmt.Sprintf("⚠️ Failed to terminate %s (%d)", msg.entry.Process, msg.entry.PID), toastError)
 			m.errMsg = fmt.Sprintf("termination failed: %v", msg.err)
 		} else {
 			m.removeEntry(msg.entry)
-			m.toast = newToast(fmt.Sprintf("✅ Terminated %s (%d)", msg.entry.Process, msg.entry.PID), toastSuccess)
+			m.toast = m.newToast(fmt.Sprintf("✅ Terminated %s (%d)", msg.entry.Process, msg.entry.PID), toastSuccess)
 			m.statusMsg = "🔄 Refreshing port list..."
-			cmds = append(cmds, loadPortsCmd(m.provider))
+			cmds = append(cmds, m.loadPortsCmd())
 		}
 		return m, tea.Batch(cmds...)
 
 	case tickMsg:
-		m.tickCount++
-		if len(accentCycle) > 0 && m.tickCount%3 == 0 {
-			m.accentIndex = (m.accentIndex + 1) % len(accentCycle)
-			m.applyAccentStyles()
-		}
-		if len(taglineCycle) > 0 && m.tickCount%8 == 0 {
-			m.taglineIndex = (m.taglineIndex + 1) % len(taglineCycle)
+		if !m.reduceMotion {
+			m.tickCount++
+			if len(m.theme.Accents) > 0 && m.tickCount%3 == 0 {
+				m.accentIndex = (m.accentIndex + 1) % len(m.theme.Accents)
+				m.applyAccentStyles()
+			}
+			if len(taglineCycle) > 0 && m.tickCount%8 == 0 {
+				m.taglineIndex = (m.taglineIndex + 1) % len(taglineCycle)
+			}
 		}
 		if m.toast.message != "" && msg.when.After(m.toast.expires) {
 			m.toast = toastState{}
 		}
-		return m, animationTickCmd()
+		return m, m.animationTickCmd()
+
+	case refreshTickMsg:
+		// A host still answering the previous listing is left to finish.
+		if m.confirm == nil && m.yank == nil && m.history == nil && !m.queryEditing && !m.tabs[msg.host].listing {
+			cmds = append(cmds, m.loadHostCmd(msg.host))
+		}
+		cmds = append(cmds, m.refreshTickCmd(msg.host))
+		return m, tea.Batch(cmds...)
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
 
 	case tea.KeyMsg:
+		if m.queryEditing {
+			return m.updateQueryInput(msg)
+		}
+
 		if m.confirm != nil {
-			switch msg.String() {
-			case "y", "Y", "enter":
-				if !m.killPending {
-					entry := *m.confirm
-					m.killPending = true
-					m.toast = newToast(fmt.Sprintf("💀🗡️ Priming SIGTERM for PID %d...", entry.PID), toastInfo)
-					cmds = append(cmds, killProcessCmd(entry))
-				}
-			case "n", "N", "esc":
-				m.confirm = nil
-				m.killPending = false
-				m.resizeList()
+			if m.killDenied {
+				return m.updateDeniedKill(msg)
 			}
-			if len(cmds) > 0 {
-				return m, tea.Batch(cmds...)
+			if m.confirm.Protection == ports.ProtectConfirm && !m.killPending {
+				return m.updateGuardedKill(msg)
+			}
+			switch {
+			case key.Matches(msg, m.keys.Confirm):
+				return m, m.confirmKill()
+			case key.Matches(msg, m.keys.Cancel):
+				m.cancelKill()
+			case key.Matches(msg, m.keys.StopUnit):
+				return m, m.unitAction(ports.UnitStop)
+			case key.Matches(msg, m.keys.RestartUnit):
+				return m, m.unitAction(ports.UnitRestart)
 			}
 			return m, nil
 		}
 
-		switch msg.String() {
-		case "q", "ctrl+c":
+		if m.yank != nil {
+			return m.updateYankMenu(msg)
+		}
+
+		if m.history != nil {
+			return m.updateHistory(msg)
+		}
+
+		if m.list.SettingFilter() {
+			// Typed characters belong to the fuzzy filter input.
+			break
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
-		case "esc":
+		case key.Matches(msg, m.keys.Back):
 			if m.helpVisible {
 				m.helpVisible = false
 				m.resizeList()
@@ -216,33 +384,67 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// When list is empty (after filtering and killing), refresh to show all ports
 				m.list.ResetFilter()
 				m.statusMsg = "🔄 Refreshing..."
-				cmds = append(cmds, loadPortsCmd(m.provider))
+				cmds = append(cmds, m.loadPortsCmd())
 				return m, tea.Batch(cmds...)
 			}
 			// Let escape fall through to list component to handle search mode exit
-		case "r":
+		case key.Matches(msg, m.keys.Refresh):
 			m.statusMsg = "🔄 Refreshing..."
-			cmds = append(cmds, loadPortsCmd(m.provider))
-		case "/":
+			cmds = append(cmds, m.loadPortsCmd())
+		case key.Matches(msg, m.keys.Filter):
 			// fall through to list for filtering shortcut.
-		case "?":
+		case m.multiHost() && key.Matches(msg, m.keys.NextHost):
+			m.cycleTab(1)
+			return m, nil
+		case m.multiHost() && key.Matches(msg, m.keys.PrevHost):
+			m.cycleTab(-1)
+			return m, nil
+		case key.Matches(msg, m.keys.ToggleMine, m.keys.ToggleSystem, m.keys.ToggleLoopback, m.keys.ToggleUDP):
+			return m, m.flipToggle(msg)
+		case key.Matches(msg, m.keys.Query):
+			m.queryEditing = true
+			m.queryErr = ""
+			m.queryInput.SetValue(m.query.String())
+			m.queryInput.CursorEnd()
+			return m, m.queryInput.Focus()
+		case key.Matches(msg, m.keys.Help):
 			m.helpVisible = !m.helpVisible
 			m.resizeList()
 			return m, nil
-		case "enter", "d":
+		case key.Matches(msg, m.keys.Open):
+			if item, ok := m.list.SelectedItem().(portItem); ok {
+				return m, m.openEntry(item.entry)
+			}
+		case key.Matches(msg, m.keys.Probe):
+			if item, ok := m.list.SelectedItem().(portItem); ok {
+				return m, m.startProbe(item.entry)
+			}
+		case key.Matches(msg, m.keys.Details):
+			m.detailsVisible = !m.detailsVisible
+			m.resizeList()
+			return m, nil
+		case key.Matches(msg, m.keys.Yank):
 			if item, ok := m.list.SelectedItem().(portItem); ok {
 				entry := item.entry
-				m.confirm = &entry
-				m.killPending = false
-				m.statusMsg = fmt.Sprintf("💀🗡️ Target locked: %s (%d)", entry.Process, entry.PID)
+				m.yank = &entry
 				m.resizeList()
 				return m, nil
 			}
+		case key.Matches(msg, m.keys.History):
+			return m, loadHistoryCmd(m.config)
+		case key.Matches(msg, m.keys.Kill):
+			if item, ok := m.list.SelectedItem().(portItem); ok {
+				return m, m.openKillModal(item.entry)
+			}
+		case key.Matches(msg, m.keys.Restart):
+			if item, ok := m.list.SelectedItem().(portItem); ok {
+				return m, m.openRestartModal(item.entry)
+			}
 		}
 	}
 
 	var cmd tea.Cmd
-	if m.confirm == nil && !m.helpVisible {
+	if m.confirm == nil && m.yank == nil && m.history == nil && !m.helpVisible {
 		m.list, cmd = m.list.Update(msg)
 		if cmd != nil {
 			cmds = append(cmds, cmd)
@@ -263,36 +465,67 @@ func (m Model) View() string {
 
 	tableHeader := m.renderTableHeader()
 	listView := m.list.View()
-	if m.confirm != nil || m.helpVisible {
+	if m.confirm != nil || m.yank != nil || m.history != nil || m.helpVisible {
 		if tableHeader != "" {
-			tableHeader = dimStyle.Render(tableHeader)
+			tableHeader = m.styles.dim.Render(tableHeader)
 		}
-		listView = dimStyle.Render(listView)
+		listView = m.styles.dim.Render(listView)
 	}
 
 	sections := []string{m.renderHeader()}
 	if tableHeader != "" {
 		sections = append(sections, tableHeader)
 	}
-	sections = append(sections,
-		listView,
-		m.renderFooter(),
-	)
+	sections = append(sections, listView)
+	if m.detailsVisible && !m.helpVisible {
+		sections = append(sections, m.renderDetails())
+	}
+	sections = append(sections, m.renderFooter())
 
 	var modal string
 
 	if m.helpVisible {
-		sections = append(sections, "", renderHelp(m.width))
+		sections = append(sections, "", renderHelp(m.keys, m.styles, m.width))
 	} else if m.confirm != nil {
-		modal = renderKillModal(*m.confirm, m.killPending, m.width)
+		modal = m.killModal()
+	} else if m.yank != nil {
+		modal = renderYankMenu(*m.yank, m.keys, m.config.Kill.Signal, m.styles)
+	} else if m.history != nil {
+		modal = renderHistory(*m.history, m.keys, m.styles, m.width)
 	}
 
 	view := strings.Join(sections, "\n")
 	if modal != "" {
-		return overlayModal(view, modal, m.width, m.height)
+		view = overlayModal(view, modal, m.width, m.height)
 	}
 
-	return view
+	// The OSC52 sequence has no width, so it rides along with the first line.
+	return m.clipboard + view
+}
+
+// confirmKill starts terminating the process shown in the kill modal.
+func (m *Model) confirmKill() tea.Cmd {
+	if m.confirm == nil || m.killPending || !m.killAllowed() {
+		return nil
+	}
+	if m.killRestart {
+		return m.startRestart()
+	}
+	entry := *m.confirm
+	m.killPending = true
+	policy := m.config.Kill.Policy()
+	m.toast = m.newToast(fmt.Sprintf("💀🗡️ Priming SIG%s for PID %d...", strings.ToUpper(policy.Signal), entry.PID), toastInfo)
+	return killProcessCmd(entry, m.killer)
+}
+
+// cancelKill closes the kill modal without touching the process.
+func (m *Model) cancelKill() {
+	m.confirm = nil
+	m.killPending = false
+	m.killDenied = false
+	m.killRestart = false
+	m.confirmInput.Blur()
+	m.resizeList()
 }
 
 func (m *Model) resizeList() {
@@ -303,7 +536,13 @@ func (m *Model) resizeList() {
 	reserve := headerLines + tableHeaderLines + footerLines
 	switch {
 	case m.helpVisible:
-		reserve += helpLines
+		reserve += helpChromeLines + m.keys.helpRowCount()
+	case m.detailsVisible:
+		reserve += detailLines
+	}
+
+	if m.multiHost() {
+		reserve += hostTabLines
 	}
 
 	height := max(3, m.height-reserve)
@@ -316,84 +555,109 @@ func (m *Model) recalcColumns() {
 	if width <= 0 {
 		return
 	}
+	m.layout.fit(width)
+}
 
-	separatorWidth := lipgloss.Width(columnSeparator)
-	const separatorCount = 5
-
-	available := width - separatorWidth*separatorCount
-	if available < 5 {
-		available = 5
-	}
-
-	columns := columnWidths{}
-	specs := []struct {
-		field   *int
-		min     int
-		desired int
-	}{
-		{&columns.proto, 3, 4},
-		{&columns.port, 4, 5},
-		{&columns.process, 12, 18},
-		{&columns.pid, 5, 6},
-		{&columns.user, 6, 12},
-		{&columns.address, 16, 30},
+// updateQueryInput routes key presses to the query prompt while it is open.
+func (m Model) updateQueryInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		q, err := query.Parse(m.queryInput.Value())
+		if err != nil {
+			m.queryErr = err.Error()
+			return m, nil
+		}
+		m.query = q
+		m.queryEditing = false
+		m.queryErr = ""
+		m.queryInput.Blur()
+		shown := m.applyFilters()
+		if q.Empty() {
+			m.statusMsg = "🧹 Query cleared"
+		} else {
+			m.statusMsg = fmt.Sprintf("🧬 Query matched %d of %d ports", shown, len(m.entries))
+		}
+		return m, nil
+	case "esc", "ctrl+c":
+		m.queryEditing = false
+		m.queryErr = ""
+		m.queryInput.Blur()
+		return m, nil
 	}
 
-	remaining := available
-	for i := range specs {
-		*specs[i].field = specs[i].min
-		remaining -= specs[i].min
-	}
+	var cmd tea.Cmd
+	m.queryInput, cmd = m.queryInput.Update(msg)
+	return m, cmd
+}
 
-	if remaining < 0 {
-		deficit := -remaining
-		order := []int{len(specs) - 1, 3, 4, 1, 0, 2}
-		for _, idx := range order {
-			if deficit == 0 {
-				break
-			}
-			current := *specs[idx].field
-			reducible := current - 1
-			if reducible <= 0 {
-				continue
-			}
-			cut := min(deficit, reducible)
-			*specs[idx].field = current - cut
-			deficit -= cut
-		}
-		remaining = 0
+// flipToggle switches the quick filter bound to the pressed key, re-filters
+// the list and persists the new toggle set.
+func (m *Model) flipToggle(msg tea.KeyMsg) tea.Cmd {
+	var label string
+	var on bool
+	switch {
+	case key.Matches(msg, m.keys.ToggleMine):
+		m.toggles.MineOnly = !m.toggles.MineOnly
+		label, on = "only my ports", m.toggles.MineOnly
+	case key.Matches(msg, m.keys.ToggleSystem):
+		m.toggles.HideSystem = !m.toggles.HideSystem
+		label, on = "hide ports < 1024", m.toggles.HideSystem
+	case key.Matches(msg, m.keys.ToggleLoopback):
+		m.toggles.HideLoopback = !m.toggles.HideLoopback
+		label, on = "hide loopback", m.toggles.HideLoopback
+	case key.Matches(msg, m.keys.ToggleUDP):
+		m.toggles.HideUDP = !m.toggles.HideUDP
+		label, on = "hide UDP", m.toggles.HideUDP
+	}
+
+	shown := m.applyFilters()
+	state := "off"
+	if on {
+		state = "on"
+	}
+	m.statusMsg = fmt.Sprintf("🎛️ %s %s · %d shown", label, state, shown)
+
+	toggles := m.toggles
+	return func() tea.Msg {
+		return prefsSavedMsg{err: prefs.Save(prefs.Prefs{Toggles: &toggles})}
 	}
+}
 
-	for remaining > 0 {
-		progress := false
-		for i := range specs {
-			if remaining == 0 {
-				break
-			}
-			current := *specs[i].field
-			if current >= specs[i].desired {
-				continue
-			}
-			add := min(specs[i].desired-current, remaining)
-			*specs[i].field = current + add
-			remaining -= add
-			progress = true
-		}
-		if !progress {
-			for i := range specs {
-				if remaining == 0 {
-					break
-				}
-				*specs[i].field++
-				remaining--
-			}
+// applyFilters rebuilds the list items from the loaded entries that satisfy the
+// quick toggles and the active query, returning how many were kept.
+func (m *Model) applyFilters() int {
+	matched := m.query.Filter(m.entries)
+	if m.sortColumn != "" {
+		matched = append([]ports.Port(nil), matched...)
+		sortPorts(matched, m.sortColumn, m.sortDesc)
+	}
+	items := make([]list.Item, 0, len(matched))
+	for _, entry := range matched {
+		if !m.toggles.Match(entry, m.currentUser) {
+			continue
 		}
+		items = append(items, portItem{entry: entry, layout: m.layout})
 	}
+	m.list.SetItems(items)
+	m.recalcColumns()
+	return len(items)
+}
 
-	m.columns = columns
+// sameSocket reports whether a and b describe the same process and port on
+// the same host.
+func sameSocket(a, b ports.Port) bool {
+	return a.Host == b.Host && a.NetNS == b.NetNS && a.PID == b.PID && a.Port == b.Port && strings.EqualFold(a.Protocol, b.Protocol)
 }
 
 func (m *Model) removeEntry(entry ports.Port) {
+	m.forgetEntry(entry)
+	for i, e := range m.entries {
+		if sameSocket(e, entry) {
+			m.entries = append(m.entries[:i:i], m.entries[i+1:]...)
+			break
+		}
+	}
+
 	items := m.list.Items()
 	if len(items) == 0 {
 		return
@@ -407,11 +671,11 @@ func (m *Model) removeEntry(entry ports.Port) {
 			filtered = append(filtered, item)
 			continue
 		}
-		if !removed && pi.entry.PID == entry.PID && pi.entry.Port == entry.Port && strings.EqualFold(pi.entry.Protocol, entry.Protocol) {
+		if !removed && sameSocket(pi.entry, entry) {
 			removed = true
 			continue
 		}
-		pi.layout = &m.columns
+		pi.layout = m.layout
 		filtered = append(filtered, pi)
 	}
 
@@ -429,103 +693,50 @@ func (m *Model) removeEntry(entry ports.Port) {
 	m.recalcColumns()
 }
 
-func loadPortsCmd(p ports.Provider) tea.Cmd {
-	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-
-		entries, err := p.List(ctx)
-		return portsLoadedMsg{entries: entries, err: err}
-	}
+func newQueryInput(st styles) textinput.Model {
+	input := textinput.New()
+	input.Prompt = "⌘ "
+	input.Placeholder = "port:3000-3999 proc:node user:!root proto:udp addr:127.0.0.1 state:listen"
+	input.PromptStyle = st.filterPrompt
+	input.TextStyle = st.inputText
+	input.PlaceholderStyle = st.hint
+	input.CharLimit = 256
+	return input
 }
 
-func killProcessCmd(entry ports.Port) tea.Cmd {
+// killProcessCmd terminates entry's process. The kill modal has already
+// asked for any confirmation a protection rule demands.
+func killProcessCmd(entry ports.Port, k *killer.Killer) tea.Cmd {
 	return func() tea.Msg {
-		err := ports.Terminate(entry.PID)
-		return killResultMsg{entry: entry, err: err}
+		result := k.Kill(entry, true)
+		return killResultMsg{entry: entry, err: result.Err, historyErr: result.HistoryErr, auditErr: result.AuditErr}
 	}
 }
 
-func animationTickCmd() tea.Cmd {
-	return tea.Tick(120*time.Millisecond, func(t time.Time) tea.Msg {
+// animationTickCmd drives the accent cycle and toast expiry. With reduced
+// motion it only ticks once a second so toasts still expire without the
+// constant redraws.
+func (m Model) animationTickCmd() tea.Cmd {
+	interval := 120 * time.Millisecond
+	if m.reduceMotion {
+		interval = time.Second
+	}
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
 		return tickMsg{when: t}
 	})
 }
 
 type portItem struct {
 	entry  ports.Port
-	layout *columnWidths
+	layout *columnLayout
 }
 
 func (p portItem) Title() string {
-	layout := defaultColumns()
-	if p.layout != nil {
-		layout = *p.layout
-	}
-	
-	// Creative protocol indicators
-	proto := strings.ToUpper(p.entry.Protocol)
-	var protoIcon string
-	switch proto {
-	case "TCP":
-		protoIcon = "🔗"
-	case "UDP":
-		protoIcon = "📡"
-	case "HTTP":
-		protoIcon = "🌐"
-	case "HTTPS":
-		protoIcon = "🔐"
-	default:
-		protoIcon = "⚡"
-	}
-	
-	// State with cyberpunk indicators
-	state := strings.ToLower(p.entry.State)
-	var stateIcon string
-	switch state {
-	case "listening":
-		stateIcon = "🎯"
-	case "established":
-		stateIcon = "🔥"
-	case "close_wait":
-		stateIcon = "⏳"
-	case "time_wait":
-		stateIcon = "💭"
-	default:
-		stateIcon = "🌀"
-		if state == "" {
-			state = "listening"
-			stateIcon = "🎯"
-		}
-	}
-	
-	// Port classification with visual indicators
-	var portClassIcon string
-	port := p.entry.Port
-	switch {
-	case port < 1024:
-		portClassIcon = "👑" // System/privileged ports
-	case port >= 1024 && port < 49152:
-		portClassIcon = "🎪" // Registered ports
-	default:
-		portClassIcon = "🎲" // Dynamic/private ports
-	}
-	
-	// Process name with visual enhancement
-	process := p.entry.Process
-	if state != "" {
-		process = fmt.Sprintf("%s [%s]", process, state)
+	layout := p.layout
+	if layout == nil {
+		layout = newColumnLayout(config.Default().Columns, nil)
 	}
-
-	columns := []string{
-		padded(fmt.Sprintf("%s %s", protoIcon, proto), layout.proto),
-		padded(fmt.Sprintf("%s %d", portClassIcon, p.entry.Port), layout.port),
-		padded(fmt.Sprintf("%s %s", stateIcon, process), layout.process),
-		padded(fmt.Sprintf("💀 %d", p.entry.PID), layout.pid),
-		padded(fmt.Sprintf("👤 %s", p.entry.User), layout.user),
-		padded(fmt.Sprintf("🌍 %s", p.entry.Address), layout.address),
-	}
-	return "▶ " + strings.Join(columns, " ┃ ")
+	return layout.row(p.entry)
 }
 
 func (p portItem) Description() string {
@@ -533,11 +744,11 @@ func (p portItem) Description() string {
 }
 
 func (p portItem) FilterValue() string {
-	return fmt.Sprintf("%s %d %s %s %s", p.entry.Process, p.entry.Port, p.entry.Protocol, p.entry.User, p.entry.State)
+	return fmt.Sprintf("%s %d %s %s %s %s", p.entry.Process, p.entry.Port, p.entry.Protocol, p.entry.User, p.entry.State, p.entry.Service)
 }
 
-func newToast(message string, kind toastKind) toastState {
-	return toastState{message: message, kind: kind, expires: time.Now().Add(3 * time.Second)}
+func (m Model) newToast(message string, kind toastKind) toastState {
+	return toastState{message: message, kind: kind, expires: time.Now().Add(m.config.ToastDuration)}
 }
 
 func padded(text string, width int) string {
@@ -545,28 +756,30 @@ func padded(text string, width int) string {
 		return ""
 	}
 	if lipgloss.Width(text) > width {
-		runes := []rune(text)
-		limit := min(len(runes), max(1, width-1))
-		return string(runes[:limit]) + "..."
+		return ansi.Truncate(text, width, "…")
 	}
 	return text + strings.Repeat(" ", max(0, width-lipgloss.Width(text)))
 }
 
 func (m Model) accentColor(offset int) string {
-	if len(accentCycle) == 0 {
-		return matrixAccentNeon
+	accents := m.theme.Accents
+	if len(accents) == 0 {
+		return m.theme.Accent
 	}
-	idx := (m.accentIndex + offset) % len(accentCycle)
+	idx := (m.accentIndex + offset) % len(accents)
 	if idx < 0 {
-		idx += len(accentCycle)
+		idx += len(accents)
 	}
-	return accentCycle[idx]
+	return accents[idx]
 }
 
 func (m Model) generateMatrixRain(width int) string {
 	if width <= 0 {
 		return ""
 	}
+	if m.reduceMotion {
+		return strings.Repeat(" ", width)
+	}
 	rain := make([]string, width)
 	for i := 0; i < width; i++ {
 		if (m.tickCount+i)%7 == 0 {
@@ -575,13 +788,13 @@ func (m Model) generateMatrixRain(width int) string {
 			var color string
 			switch intensity {
 			case 0:
-				color = matrixAccentGreen
+				color = m.theme.Success
 			case 1:
-				color = matrixTextDim
+				color = m.theme.TextMuted
 			case 2:
-				color = matrixTextSubtle
+				color = m.theme.TextSubtle
 			default:
-				color = matrixBorder
+				color = m.theme.Border
 			}
 			rain[i] = lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(matrixChars[charIdx])
 		} else {
@@ -592,7 +805,7 @@ func (m Model) generateMatrixRain(width int) string {
 }
 
 func (m Model) generateGlitch(text string) string {
-	if m.tickCount%20 == 0 && len(text) > 0 {
+	if !m.reduceMotion && m.tickCount%20 == 0 && len(text) > 0 {
 		runes := []rune(text)
 		glitchIdx := m.tickCount % len(runes)
 		if glitchIdx < len(glitchChars) {
@@ -611,12 +824,15 @@ func (m Model) generateDigitalNoise(length int) string {
 	if length <= 0 {
 		return ""
 	}
+	if m.reduceMotion {
+		return strings.Repeat(" ", length)
+	}
 	noise := make([]string, length)
 	for i := 0; i < length; i++ {
 		if (m.tickCount+i)%11 == 0 {
 			charIdx := (m.tickCount + i*3) % len(glitchChars)
 			noise[i] = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixTextSubtle)).
+				Foreground(lipgloss.Color(m.theme.TextSubtle)).
 				Render(glitchChars[charIdx])
 		} else {
 			noise[i] = " "
@@ -629,8 +845,8 @@ func (m *Model) applyAccentStyles() {
 	accentMain := lipgloss.Color(m.accentColor(0))
 	accentSoft := lipgloss.Color(m.accentColor(2))
 
-	m.list.Styles.FilterCursor = filterCursorBase.Foreground(accentMain)
-	m.list.Styles.TitleBar = filterBarStyle.Copy().BorderForeground(accentSoft)
+	m.list.Styles.FilterCursor = m.styles.filterCursor.Foreground(accentMain)
+	m.list.Styles.TitleBar = m.styles.filterBar.BorderForeground(accentSoft)
 }
 
 func (m Model) renderHeader() string {
@@ -659,28 +875,70 @@ func (m Model) renderHeader() string {
 		taglineText = taglineCycle[m.taglineIndex%len(taglineCycle)]
 	}
 	glitchedTagline := m.generateGlitch(taglineText)
-	tagline := headerTaglineBase.Foreground(accentSecondary).Render(glitchedTagline)
+	tagline := m.styles.headerTagline.Foreground(accentSecondary).Render(glitchedTagline)
 	
 	// System status with cyberpunk flair
 	statusLine := fmt.Sprintf("【 QUANTUM CORE ACTIVE 】【 %d TARGETS ACQUIRED 】【 MATRIX SYNCHRONIZED 】", len(m.list.Items()))
-	systemStatus := headerSubtitleStyle.Foreground(accentTertiary).Render(statusLine)
+	for _, chip := range m.toggleChips() {
+		statusLine += fmt.Sprintf("【 %s 】", chip)
+	}
+	if !m.query.Empty() {
+		statusLine += fmt.Sprintf("【 ⌘ %s 】", m.query.String())
+	}
+	if hidden := ports.HiddenCount(m.entries); hidden > 0 {
+		statusLine += fmt.Sprintf("【 🙈 %s OWNED BY UNKNOWN PIDS 】", pluralSockets(hidden))
+	}
+	if host := m.tabs[0].Name; host != "" && !m.multiHost() {
+		statusLine = fmt.Sprintf("【 🌐 %s 】", strings.ToUpper(host)) + statusLine
+	}
+	systemStatus := m.styles.headerSubtitle.Foreground(accentTertiary).Render(statusLine)
 	
 	// Dynamic border with digital noise
 	borderChars := []string{"═", "━", "▬", "⬛", "▪", "▫", "░", "▒", "▓"}
 	borderChar := borderChars[m.tickCount%len(borderChars)]
-	border := headerBorderBase.Foreground(accentQuad).Render(strings.Repeat(borderChar, max(0, m.width)))
+	border := m.styles.headerBorder.Foreground(accentQuad).Render(strings.Repeat(borderChar, max(0, m.width)))
 	
 	// Digital noise line
 	digitalNoise := m.generateDigitalNoise(m.width)
 
-	return lipgloss.JoinVertical(lipgloss.Left,
+	lines := []string{
 		matrixRain,
 		lipgloss.PlaceHorizontal(m.width, lipgloss.Center, title),
 		lipgloss.PlaceHorizontal(m.width, lipgloss.Center, tagline),
 		lipgloss.PlaceHorizontal(m.width, lipgloss.Center, systemStatus),
 		border,
 		digitalNoise,
-	)
+	}
+	if m.multiHost() {
+		lines = append(lines, m.renderHostTabs())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// pluralSockets renders "1 SOCKET" or "3 SOCKETS".
+func pluralSockets(n int) string {
+	if n == 1 {
+		return "1 SOCKET"
+	}
+	return fmt.Sprintf("%d SOCKETS", n)
+}
+
+// toggleChips lists short labels for the enabled quick filters.
+func (m Model) toggleChips() []string {
+	var chips []string
+	if m.toggles.MineOnly {
+		chips = append(chips, "👤 MINE")
+	}
+	if m.toggles.HideSystem {
+		chips = append(chips, "👑 ≥1024")
+	}
+	if m.toggles.HideLoopback {
+		chips = append(chips, "🔁 NO LOOPBACK")
+	}
+	if m.toggles.HideUDP {
+		chips = append(chips, "📡 NO UDP")
+	}
+	return chips
 }
 
 func (m Model) renderTableHeader() string {
@@ -688,23 +946,31 @@ func (m Model) renderTableHeader() string {
 		return ""
 	}
 
-	columnTexts := []string{"PROTO", "PORT", "PROCESS", "PID", "USER", "ADDRESS"}
-	columnWidths := []int{m.columns.proto, m.columns.port, m.columns.process, m.columns.pid, m.columns.user, m.columns.address}
 	accentSequence := []string{
 		m.accentColor(0),
 		m.accentColor(1),
 		m.accentColor(2),
-		matrixAccentGold,
-		matrixAccentGreen,
+		m.theme.Warning,
+		m.theme.Success,
 		m.accentColor(0),
 	}
-	styledColumns := make([]string, len(columnTexts))
-	for i, text := range columnTexts {
-		style := tableHeaderBase.Foreground(lipgloss.Color(accentSequence[i%len(accentSequence)]))
-		styledColumns[i] = style.Render(padded(text, columnWidths[i]))
+	styledColumns := make([]string, len(m.layout.visible))
+	for i, col := range m.layout.visible {
+		style := m.styles.tableHeader.PaddingLeft(0).Foreground(lipgloss.Color(accentSequence[i%len(accentSequence)]))
+		title := col.title
+		if col.name == m.sortColumn {
+			arrow := " ▲"
+			if m.sortDesc {
+				arrow = " ▼"
+			}
+			title += arrow
+		}
+		styledColumns[i] = style.Render(padded(title, m.layout.widths[i]))
 	}
 
-	row := strings.Join(styledColumns, tableSeparatorStyle.Render(columnSeparator))
+	// Indent by the delegate padding and row prefix so titles sit over cells.
+	indent := m.styles.tableHeader.Render(strings.Repeat(" ", lipgloss.Width(rowPrefix)))
+	row := indent + strings.Join(styledColumns, m.styles.tableSeparator.Render(columnSeparator))
 	return lipgloss.PlaceHorizontal(m.width, lipgloss.Left, row)
 }
 
@@ -712,29 +978,34 @@ func (m Model) renderFooter() string {
 	var lines []string
 	
 	// Status with cyberpunk styling
-	if m.errMsg != "" {
+	if m.queryEditing {
+		lines = append(lines, fmt.Sprintf("【 ⌘ QUERY 】 %s", m.queryInput.View()))
+		if m.queryErr != "" {
+			lines = append(lines, m.styles.errorText.Render(fmt.Sprintf("【 ⚠️  INVALID QUERY ⚠️  】 %s", m.queryErr)))
+		}
+	} else if m.errMsg != "" {
 		errorMsg := fmt.Sprintf("【 ⚠️  SYSTEM ERROR ⚠️  】 %s", m.errMsg)
-		lines = append(lines, errorStyle.Render(errorMsg))
+		lines = append(lines, m.styles.errorText.Render(errorMsg))
 	} else if m.statusMsg != "" {
 		statusMsg := fmt.Sprintf("【 ⚡ STATUS ⚡ 】 %s", m.statusMsg)
-		lines = append(lines, statusStyle.Render(statusMsg))
+		lines = append(lines, m.styles.status.Render(statusMsg))
 	}
 
 	if m.toast.message != "" {
-		lines = append(lines, toastStyle(m.toast))
+		lines = append(lines, m.styles.toast(m.toast))
 	}
 
 	// Enhanced control hints with cyberpunk aesthetics
-	controlSections := []string{
-		"🎯 j/k/↑↓ NAVIGATE",
-		"💀 enter/d TERMINATE",
-		"🔄 r REFRESH",
-		"🔍 / SCAN",
-		"❓ ? INFO",
-		"💨 q ESCAPE",
+	var controlSections []string
+	for _, hint := range m.keys.footerHints() {
+		keys := bindingKeys(hint.bindings)
+		if keys == "" {
+			continue
+		}
+		controlSections = append(controlSections, fmt.Sprintf("%s %s %s", hint.icon, keys, hint.label))
 	}
 	controlHint := strings.Join(controlSections, "  ║  ")
-	hint := hintStyle.Render(fmt.Sprintf("【 COMMAND MATRIX 】 %s", controlHint))
+	hint := m.styles.hint.Render(fmt.Sprintf("【 COMMAND MATRIX 】 %s", controlHint))
 	lines = append(lines, hint)
 	
 	// Digital noise footer
@@ -743,12 +1014,14 @@ func (m Model) renderFooter() string {
 
 	rendered := make([]string, len(lines))
 	for i, line := range lines {
-		rendered[i] = lipgloss.PlaceHorizontal(m.width, lipgloss.Left, line)
+		rendered[i] = lipgloss.PlaceHorizontal(m.width, lipgloss.Left, ansi.Truncate(line, m.width, "…"))
 	}
 	return strings.Join(rendered, "\n")
 }
 
-func renderKillModal(entry ports.Port, inFlight bool, width int) string {
+// renderKillModal draws the kill confirmation.
+func renderKillModal(state killModalState, keys keyMap, st styles, width int) string {
+	entry, inFlight, denied := state.entry, state.inFlight, state.denied
 	// Epic ASCII art warning
 	warningArt := `
     ███████╗██╗    ██╗ █████╗ ██████╗ ███╗   ██╗██╗███╗   ██╗ ██████╗ 
@@ -762,42 +1035,91 @@ func renderKillModal(entry ports.Port, inFlight bool, width int) string {
 		entry.Process, strings.ToUpper(entry.Protocol), entry.Port, entry.PID)
 	
 	var status string
-	if inFlight {
+	switch {
+	case denied:
+		status = fmt.Sprintf("🔒 PERMISSION DENIED 🔒\n%s (PID %d) belongs to %s · retry as root?", entry.Process, entry.PID, orDash(entry.User))
+	case inFlight:
 		status = "🔥💀 QUANTUM TERMINATION SEQUENCE INITIATED 💀🔥\n⚡⚡⚡ NEURAL PATHWAYS SEVERING ⚡⚡⚡"
-	} else {
+	case entry.Protection == ports.ProtectRefuse:
+		status = fmt.Sprintf("⛔ PROTECTED PROCESS · TERMINATION REFUSED ⛔\nMatches protection rule: %s", entry.ProtectedBy)
+	case entry.Protection == ports.ProtectConfirm && state.restart:
+		status = fmt.Sprintf("🛡️  PROTECTED PROCESS (%s) 🛡️\nType %s and press enter to restart it", entry.ProtectedBy, entry.Process)
+	case entry.Protection == ports.ProtectConfirm:
+		status = fmt.Sprintf("🛡️  PROTECTED PROCESS (%s) 🛡️\nType %s and press enter to eliminate it", entry.ProtectedBy, entry.Process)
+	case state.restart:
+		status = fmt.Sprintf("🔁 REBOOT TARGET? 🔁\nIt will be stopped, then relaunched with the same command until :%d answers again", entry.Port)
+	default:
 		status = "💀 INITIATE DIGITAL ANNIHILATION PROTOCOL? 💀\n⚔️  WARNING: PROCESS WILL BE ELIMINATED ⚔️"
 	}
 
+	confirmCaption, cancelCaption := killModalCaptions(keys, state)
+	buttons := []string{st.modalCancel.Render(cancelCaption)}
+	if confirmCaption != "" {
+		buttons = []string{st.modalConfirm.Render(confirmCaption), st.modalSpacer.Render("    "), buttons[0]}
+	}
 	modalWidth := clamp(width-4, 40, 80)
-	innerWidth := modalWidth - modalStyle.GetPaddingLeft() - modalStyle.GetPaddingRight()
+	innerWidth := modalWidth - st.modal.GetPaddingLeft() - st.modal.GetPaddingRight()
 	if innerWidth < 20 {
 		innerWidth = 20
 	}
 
 	lines := []string{
-		modalTitleBase.Render(warningArt),
+		st.modalTitle.Render(warningArt),
 		"",
-		modalSubtitleStyle.Render(subtitle),
+		st.modalSubtitle.Render(subtitle),
 		"",
-		modalStatusBase.Render(status),
+		st.modalStatus.Render(status),
 		"",
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			modalConfirmBase.Render("💀⚔️  [Y] EXECUTE TERMINATION"),
-			modalActionSpacer.Render("    "),
-			modalCancelBase.Render("🛡️  [N] ABORT MISSION"),
-		),
 	}
+	if state.prompt != "" && !inFlight && !denied {
+		lines = append(lines, state.prompt, "")
+	}
+	if stop, restart := unitModalCaptions(keys, state); stop != "" {
+		lines = append(lines,
+			st.modalStatus.Render(fmt.Sprintf("⚙️  Managed by %s · systemd may restart a killed process", unitLabel(entry))),
+			"",
+			lipgloss.JoinHorizontal(lipgloss.Left, st.modalCancel.Render(stop), st.modalSpacer.Render("    "), st.modalCancel.Render(restart)),
+			"",
+		)
+	}
+	lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left, buttons...))
 
 	contentLines := make([]string, len(lines))
 	for i, line := range lines {
-		contentLines[i] = modalContentStyle.Width(innerWidth).Render(line)
+		contentLines[i] = st.modalContent.Width(innerWidth).Render(line)
 	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, contentLines...)
-	return modalStyle.Width(modalWidth).Render(content)
+	return st.modal.Width(modalWidth).Render(content)
 }
 
-func renderHelp(width int) string {
+// killModalCaptions returns the confirm and cancel button labels; mouse hit
+// testing looks for the same text. Refused kills have no confirm button, and
+// the name prompt of confirmed ones takes enter and esc instead of the
+// configured keys. After a permission error the confirm button retries with
+// sudo.
+func killModalCaptions(keys keyMap, state killModalState) (string, string) {
+	protection := state.entry.Protection
+	confirmKey, cancelKey := primaryKey(keys.Confirm), primaryKey(keys.Cancel)
+	if state.denied {
+		return fmt.Sprintf("🔓 [%s] RETRY WITH SUDO", strings.ToUpper(primaryKey(keys.Sudo))),
+			fmt.Sprintf("🛡️  [%s] ABORT MISSION", strings.ToUpper(cancelKey))
+	}
+	if protection == ports.ProtectConfirm {
+		confirmKey, cancelKey = "enter", "esc"
+	}
+	confirm := fmt.Sprintf("💀⚔️  [%s] EXECUTE TERMINATION", strings.ToUpper(confirmKey))
+	if state.restart {
+		confirm = fmt.Sprintf("🔁 [%s] RESTART PROCESS", strings.ToUpper(confirmKey))
+	}
+	cancel := fmt.Sprintf("🛡️  [%s] ABORT MISSION", strings.ToUpper(cancelKey))
+	if protection == ports.ProtectRefuse {
+		confirm = ""
+	}
+	return confirm, cancel
+}
+
+func renderHelp(keys keyMap, st styles, width int) string {
 	helpHeader := `
     ██╗  ██╗███████╗██╗     ██████╗     ███╗   ███╗ █████╗ ████████╗██████╗ ██╗██╗  ██╗
     ██║  ██║██╔════╝██║     ██╔══██╗    ████╗ ████║██╔══██╗╚══██╔══╝██╔══██╗██║╚██╗██╔╝
@@ -806,70 +1128,39 @@ func renderHelp(width int) string {
     ██║  ██║███████╗███████╗██║         ██║ ╚═╝ ██║██║  ██║   ██║   ██║  ██║██║██╔╝ ██╗
     ╚═╝  ╚═╝╚══════╝╚══════╝╚═╝         ╚═╝     ╚═╝╚═╝  ╚═╝   ╚═╝   ╚═╝  ╚═╝╚═╝╚═╝  ╚═╝`
 
-	commandSections := [][]string{
-		{"【 NAVIGATION PROTOCOLS 】", "", ""},
-		{"🎯 Movement", "j/k ↑↓", "Navigate through targets"},
-		{"🎯 Selection", "enter", "Select current target"},
-		{"", "", ""},
-		{"【 COMBAT OPERATIONS 】", "", ""},
-		{"💀 Terminate", "d/enter", "Execute termination protocol"},
-		{"⚔️  Confirm", "y/Y", "Confirm elimination"},
-		{"🛡️  Abort", "n/N/esc", "Abort current operation"},
-		{"", "", ""},
-		{"【 SYSTEM OPERATIONS 】", "", ""},
-		{"🔄 Refresh", "r", "Reload target matrix"},
-		{"🔍 Scan", "/", "Initiate search protocol"},
-		{"💨 Escape", "esc", "Exit search mode"},
-		{"❓ Info", "?", "Toggle command matrix"},
-		{"💨 Logout", "q/ctrl+c", "Exit system"},
-	}
-
-	rows := make([]string, 0, len(commandSections))
-	for _, section := range commandSections {
-		if section[0] == "" {
+	var rows []string
+	for i, section := range keys.helpSections() {
+		if i > 0 {
 			rows = append(rows, "")
-		} else if section[1] == "" {
-			// Header section
-			headerStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixAccentNeon)).
-				Bold(true)
-			rows = append(rows, headerStyle.Render(section[0]))
-		} else {
-			// Command row
-			commandStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(matrixText))
-			keyStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixAccentPink)).
-				Bold(true)
-			descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(matrixTextDim))
-			
+		}
+		rows = append(rows, st.helpSection.Render(section.title))
+		for _, entry := range section.rows {
 			row := fmt.Sprintf("%-20s %-12s %s",
-				commandStyle.Render(section[0]),
-				keyStyle.Render(section[1]),
-				descStyle.Render(section[2]))
+				st.helpCommand.Render(entry.name),
+				st.helpKey.Render(bindingKeys(entry.bindings)),
+				st.helpDesc.Render(entry.description()))
 			rows = append(rows, row)
 		}
 	}
 
-	headerStyled := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(matrixAccentGold)).
-		Render(helpHeader)
+	headerStyled := st.helpHeader.Render(helpHeader)
 	
 	body := lipgloss.JoinVertical(lipgloss.Left, 
 		headerStyled,
 		"",
 		strings.Join(rows, "\n"))
-	panel := helpPanelStyle.Render(body)
+	panel := st.helpPanel.Render(body)
 	return lipgloss.PlaceHorizontal(width, lipgloss.Center, panel)
 }
 
-func toastStyle(t toastState) string {
+func (st styles) toast(t toastState) string {
 	switch t.kind {
 	case toastSuccess:
-		return successToastStyle.Render(t.message)
+		return st.successToast.Render(t.message)
 	case toastError:
-		return errorToastStyle.Render(t.message)
+		return st.errorToast.Render(t.message)
 	default:
-		return infoToastStyle.Render(t.message)
+		return st.infoToast.Render(t.message)
 	}
 }
 
@@ -940,36 +1231,6 @@ func clamp(value, low, high int) int {
 	return value
 }
 
-const (
-	// Matrix/Cyberpunk Color Palette
-	matrixBlack       = "#000000"
-	matrixDeepDark    = "#0a0a0a"
-	matrixDark        = "#0f0f23"
-	matrixSurface     = "#1a1a2e"
-	matrixPanel       = "#16213e"
-	matrixBorder      = "#0f3460"
-	matrixText        = "#00ff41"
-	matrixTextDim     = "#00cc33"
-	matrixTextSubtle  = "#009933"
-	matrixAccentNeon  = "#00ffff"
-	matrixAccentPink  = "#ff007f"
-	matrixAccentPurple= "#9d4edd"
-	matrixAccentBlue  = "#0077be"
-	matrixAccentGreen = "#39ff14"
-	matrixAccentRed   = "#ff073a"
-	matrixAccentGold  = "#ffd700"
-	matrixWarning     = "#ffff00"
-)
-
-var accentCycle = []string{
-	matrixAccentNeon,
-	matrixAccentPink,
-	matrixAccentPurple,
-	matrixAccentBlue,
-	matrixAccentGreen,
-	matrixAccentGold,
-}
-
 var matrixChars = []string{"0", "1", "╫", "╬", "│", "┤", "┐", "└", "┴", "┬", "├", "─", "┼", "╭", "╮", "╯", "╰"}
 
 var glitchChars = []string{"█", "▉", "▊", "▋", "▌", "▍", "▎", "▏", "░", "▒", "▓", "■", "□", "▪", "▫"}
@@ -985,114 +1246,10 @@ var taglineCycle = []string{
 	"🌌 INTERDIMENSIONAL GATEWAY 🌌",
 }
 
-var (
-	listTitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(matrixText)).
-			Background(lipgloss.Color(matrixSurface)).
-			PaddingLeft(1)
-
-	listDescStyle = listTitleStyle.Copy().
-			Foreground(lipgloss.Color(matrixTextDim))
-
-	selectedTitleBase = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixBlack)).
-				Bold(true).
-				PaddingLeft(1)
-
-	selectedDescBase = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixBlack)).
-				PaddingLeft(1)
-
-	tableHeaderBase = lipgloss.NewStyle().
-			Background(lipgloss.Color(matrixPanel)).
-			Bold(true).
-			PaddingLeft(1)
-
-	tableSeparatorStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixTextSubtle))
-
-	filterBarStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color(matrixPanel)).
-			Foreground(lipgloss.Color(matrixTextDim)).
-			Padding(0, 1, 0, 1).
-			Border(lipgloss.DoubleBorder(), false, false, true, false).
-			BorderForeground(lipgloss.Color(matrixAccentNeon))
-
-	filterPromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(matrixAccentNeon)).Bold(true)
-	filterCursorBase  = lipgloss.NewStyle()
-
-	headerTitleBase = lipgloss.NewStyle().
-			Bold(true).
-			PaddingLeft(1)
-
-	headerTaglineBase = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixAccentNeon)).
-				Bold(true).
-				PaddingLeft(1)
-
-	headerSubtitleStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixTextDim)).
-				PaddingLeft(1)
-
-	headerBorderBase = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixBorder))
-
-	statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(matrixTextDim))
-	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color(matrixAccentRed)).Bold(true)
-	hintStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color(matrixTextSubtle))
-	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color(matrixTextSubtle))
-
-	modalStyle = lipgloss.NewStyle().
-			Padding(1, 3).
-			Border(lipgloss.ThickBorder()).
-			BorderForeground(lipgloss.Color(matrixAccentPink)).
-			Background(lipgloss.Color(matrixSurface))
-
-	modalContentStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixText)).
-				Background(lipgloss.Color(matrixSurface))
-
-	modalTitleBase = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(matrixAccentPink)).
-			Bold(true)
-
-	modalSubtitleStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixTextDim))
-
-	modalStatusBase = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(matrixAccentNeon))
-
-	modalConfirmBase = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixBlack)).
-				Background(lipgloss.Color(matrixAccentGreen)).
-				Bold(true).
-				Padding(0, 1)
-
-	modalCancelBase = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(matrixTextDim)).
-			Background(lipgloss.Color(matrixPanel)).
-			Padding(0, 1)
-
-	modalActionSpacer = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(matrixTextSubtle))
-
-	helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(matrixTextDim))
-
-	helpPanelStyle = lipgloss.NewStyle().
-			Padding(1, 3).
-			Border(lipgloss.DoubleBorder()).
-			BorderForeground(lipgloss.Color(matrixBorder)).
-			Background(lipgloss.Color(matrixSurface)).
-			Width(60)
-
-	infoToastStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color(matrixAccentNeon))
-	successToastStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(matrixAccentGreen))
-	errorToastStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color(matrixAccentRed))
-)
-
 const (
 	headerLines      = 10
 	tableHeaderLines = 1
 	footerLines      = 5
-	helpLines        = 15
+	helpChromeLines  = 13
+	detailLines      = 4
 )
@@ -0,0 +1,440 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+
+	"portkiller/internal/config"
+	"portkiller/internal/ports"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// listing is a provider returning fixed entries.
+type listing []ports.Port
+
+func (l listing) List(context.Context) ([]ports.Port, error) {
+	return append([]ports.Port(nil), l...), nil
+}
+
+// newTestModel returns a sized model showing entries, with its state files
+// in a temporary directory.
+func newTestModel(t *testing.T, entries ...ports.Port) Model {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	m := New(listing(entries), config.Default())
+	m = update(t, m, tea.WindowSizeMsg{Width: 160, Height: 40})
+	return update(t, m, portsLoadedMsg{entries: entries})
+}
+
+func update(t *testing.T, m Model, msgs ...tea.Msg) Model {
+	t.Helper()
+	for _, msg := range msgs {
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	return m
+}
+
+// keys turns each argument into a key press: named keys such as "enter"
+// and "esc", or runes typed one at a time.
+func keys(presses ...string) []tea.Msg {
+	named := map[string]tea.KeyType{
+		"enter": tea.KeyEnter, "esc": tea.KeyEsc, "backspace": tea.KeyBackspace,
+		"tab": tea.KeyTab, "shift+tab": tea.KeyShiftTab,
+	}
+	var msgs []tea.Msg
+	for _, press := range presses {
+		if kind, ok := named[press]; ok {
+			msgs = append(msgs, tea.KeyMsg{Type: kind})
+			continue
+		}
+		for _, r := range press {
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	return msgs
+}
+
+func click(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}
+}
+
+// locate returns the screen cell where text first starts in the rendered
+// view.
+func locate(t *testing.T, m Model, text string) (x, y int) {
+	t.Helper()
+	for y, line := range strings.Split(ansi.Strip(m.View()), "\n") {
+		if i := strings.Index(line, text); i >= 0 {
+			return ansi.StringWidth(line[:i]), y
+		}
+	}
+	t.Fatalf("%q is not on screen", text)
+	return 0, 0
+}
+
+// locateOn returns the screen column where text starts on line y.
+func locateOn(t *testing.T, m Model, y int, text string) int {
+	t.Helper()
+	lines := strings.Split(ansi.Strip(m.View()), "\n")
+	if y < len(lines) {
+		if i := strings.Index(lines[y], text); i >= 0 {
+			return ansi.StringWidth(lines[y][:i])
+		}
+	}
+	t.Fatalf("%q is not on line %d", text, y)
+	return 0
+}
+
+func TestKillModalKeys(t *testing.T) {
+	node := ports.Port{PID: 999001, Process: "node", User: "dev", Protocol: "tcp", Port: 3000, Address: "127.0.0.1", State: "LISTEN"}
+	sshd := ports.Port{PID: 999002, Process: "sshd", User: "root", Protocol: "tcp", Port: 22, Address: "*", State: "LISTEN",
+		Protection: ports.ProtectConfirm, ProtectedBy: "process=sshd"}
+	initd := ports.Port{PID: 1, Process: "init", User: "root", Protocol: "tcp", Port: 111, Address: "*", State: "LISTEN",
+		Protection: ports.ProtectRefuse, ProtectedBy: "process=init"}
+	denied := &ports.PermissionError{PID: 999002, Signal: "TERM", Err: syscall.EPERM}
+
+	cases := []struct {
+		name  string
+		entry ports.Port
+		msgs  []tea.Msg
+		// open and pending describe the kill modal afterwards; toast is
+		// part of the last toast shown.
+		open    bool
+		pending bool
+		toast   string
+	}{
+		{"unprotected kill", node, keys("enter", "y"), true, true, "Priming"},
+		{"unprotected cancel", node, keys("enter", "n"), false, false, ""},
+		{"protected ignores the confirm key", sshd, keys("enter", "y"), true, false, ""},
+		{"protected with the wrong name", sshd, keys("enter", "ssh", "enter"), true, false, `Type "sshd"`},
+		{"protected with the wrong then the right name", sshd, keys("enter", "sshx", "enter", "backspace", "d", "enter"), true, true, "Priming"},
+		{"protected esc", sshd, keys("enter", "ss", "esc"), false, false, ""},
+		{"refused", initd, keys("enter", "y", "enter"), true, false, "Refusing to kill init"},
+		{"refused cancel", initd, keys("enter", "n"), false, false, ""},
+		{"esc while denied", sshd,
+			append(keys("enter", "sshd", "enter"), killResultMsg{entry: sshd, err: denied}, tea.KeyMsg{Type: tea.KeyEsc}),
+			false, false, "Failed to terminate sshd"},
+		{"keys ignored while denied", sshd,
+			append(keys("enter", "sshd", "enter"), killResultMsg{entry: sshd, err: denied}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}),
+			true, false, "Failed to terminate sshd"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestModel(t, tc.entry).WithSudoKill([]string{"pzapp", "kill"})
+			m = update(t, m, tc.msgs...)
+			if open := m.confirm != nil; open != tc.open || m.killPending != tc.pending {
+				t.Fatalf("modal open=%v pending=%v, want open=%v pending=%v", open, m.killPending, tc.open, tc.pending)
+			}
+			if !strings.Contains(m.toast.message, tc.toast) {
+				t.Errorf("toast = %q, want it to contain %q", m.toast.message, tc.toast)
+			}
+		})
+	}
+}
+
+func TestDeniedKillOffersSudo(t *testing.T) {
+	entry := ports.Port{PID: 999003, Process: "node", User: "dev", Protocol: "tcp", Port: 3000, Address: "*", State: "LISTEN"}
+	denied := &ports.PermissionError{PID: entry.PID, Signal: "TERM", Err: syscall.EPERM}
+
+	m := newTestModel(t, entry).WithSudoKill([]string{"pzapp", "kill"})
+	m = update(t, m, append(keys("enter", "y"), killResultMsg{entry: entry, err: denied})...)
+	if m.confirm == nil || !m.killDenied {
+		t.Fatalf("modal open=%v denied=%v after a permission error, want both", m.confirm != nil, m.killDenied)
+	}
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "RETRY WITH SUDO") || strings.Contains(view, "EXECUTE TERMINATION") {
+		t.Errorf("denied modal does not offer sudo in place of the kill:\n%s", view)
+	}
+
+	// Without a sudo helper the modal closes on the error.
+	m = newTestModel(t, entry)
+	m = update(t, m, append(keys("enter", "y"), killResultMsg{entry: entry, err: denied})...)
+	if m.confirm != nil || m.killDenied {
+		t.Errorf("modal open=%v denied=%v without a sudo helper, want neither", m.confirm != nil, m.killDenied)
+	}
+}
+
+func TestKillModalButtons(t *testing.T) {
+	node := ports.Port{PID: 999004, Process: "node", User: "dev", Protocol: "tcp", Port: 3000, Address: "127.0.0.1", State: "LISTEN"}
+	initd := ports.Port{PID: 1, Process: "init", User: "root", Protocol: "tcp", Port: 111, Address: "*", State: "LISTEN",
+		Protection: ports.ProtectRefuse, ProtectedBy: "process=init"}
+
+	cases := []struct {
+		name    string
+		entry   ports.Port
+		caption string
+		button  string
+		open    bool
+		pending bool
+	}{
+		{"confirm", node, "EXECUTE TERMINATION", "confirm", true, true},
+		{"cancel", node, "ABORT MISSION", "cancel", false, false},
+		{"refused cancel", initd, "ABORT MISSION", "cancel", false, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := update(t, newTestModel(t, tc.entry), keys("enter")...)
+			x, y := locate(t, m, tc.caption)
+			if got := m.modalButtonAt(x, y); got != tc.button {
+				t.Errorf("modalButtonAt(%d, %d) = %q, want %q", x, y, got, tc.button)
+			}
+			if got := m.modalButtonAt(x, y+1); got != "" {
+				t.Errorf("modalButtonAt(%d, %d) below the button = %q, want none", x, y+1, got)
+			}
+			m = update(t, m, click(x, y))
+			if open := m.confirm != nil; open != tc.open || m.killPending != tc.pending {
+				t.Errorf("after the click modal open=%v pending=%v, want open=%v pending=%v", open, m.killPending, tc.open, tc.pending)
+			}
+		})
+	}
+}
+
+func TestRefusedKillHasNoConfirmButton(t *testing.T) {
+	initd := ports.Port{PID: 1, Process: "init", User: "root", Protocol: "tcp", Port: 111, Address: "*", State: "LISTEN",
+		Protection: ports.ProtectRefuse, ProtectedBy: "process=init"}
+	m := update(t, newTestModel(t, initd), keys("enter")...)
+
+	if view := ansi.Strip(m.View()); strings.Contains(view, "EXECUTE TERMINATION") {
+		t.Errorf("refused kill modal shows a confirm button:\n%s", view)
+	}
+	// The confirm button would share the cancel button's line.
+	_, y := locate(t, m, "ABORT MISSION")
+	for x := range m.width {
+		if got := m.modalButtonAt(x, y); got == "confirm" {
+			t.Fatalf("modalButtonAt(%d, %d) = confirm on a refused kill", x, y)
+		}
+	}
+}
+
+// newHostsModel returns a model with this machine and a remote "build" tab,
+// both loaded.
+func newHostsModel(t *testing.T) Model {
+	t.Helper()
+	local := []ports.Port{{PID: 999010, Process: "node", User: "dev", Protocol: "tcp", Port: 3000, Address: "*", State: "LISTEN"}}
+	remote := []ports.Port{{PID: 4100, Process: "nginx", User: "root", Protocol: "tcp", Port: 80, Address: "*", State: "LISTEN"}}
+
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	m := New(listing(local), config.Default()).WithHosts([]Host{{Name: "build", Provider: listing(remote)}})
+	return update(t, m,
+		tea.WindowSizeMsg{Width: 160, Height: 40},
+		portsLoadedMsg{host: 0, entries: local},
+		portsLoadedMsg{host: 1, entries: remote},
+	)
+}
+
+func TestTabSwitchResetsSortColumn(t *testing.T) {
+	cases := []struct {
+		name string
+		// column is clicked in the merged view before switching back to
+		// this machine's tab.
+		column string
+		want   string
+	}{
+		{"host column", "host", ""},
+		{"shared column", "port", "port"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := update(t, newHostsModel(t), keys("shift+tab")...)
+			if !m.allHosts() {
+				t.Fatalf("shift+tab from the first tab showed tab %d, want the merged view", m.tab)
+			}
+			y := lipgloss.Height(m.renderHeader())
+			m = update(t, m, click(locateOn(t, m, y, strings.ToUpper(tc.column)), y))
+			if m.sortColumn != tc.column {
+				t.Fatalf("clicking %s sorted by %q", tc.column, m.sortColumn)
+			}
+
+			m = update(t, m, keys("tab")...)
+			if m.tab != 0 || m.sortColumn != tc.want {
+				t.Errorf("after tab: tab %d sorted by %q, want tab 0 sorted by %q", m.tab, m.sortColumn, tc.want)
+			}
+		})
+	}
+}
+
+func TestHostTabAt(t *testing.T) {
+	m := newHostsModel(t)
+	line := lipgloss.Height(m.renderHeader()) - hostTabLines
+	cases := []struct {
+		label string
+		want  int
+	}{
+		{"localhost", 0},
+		{"build", 1},
+		{"all hosts", 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.label, func(t *testing.T) {
+			x := locateOn(t, m, line, tc.label)
+			if got := m.hostTabAt(x); got != tc.want {
+				t.Errorf("hostTabAt(%d) = %d, want %d", x, got, tc.want)
+			}
+			if got := update(t, m, click(x, line)); got.tab != tc.want {
+				t.Errorf("clicking %q showed tab %d, want %d", tc.label, got.tab, tc.want)
+			}
+		})
+	}
+	if got := m.hostTabAt(m.width - 1); got != -1 {
+		t.Errorf("hostTabAt(%d) past the last tab = %d, want -1", m.width-1, got)
+	}
+}
+
+func TestCycleTab(t *testing.T) {
+	cases := []struct {
+		from, delta, want int
+	}{
+		{0, 1, 1},
+		{1, 1, 2},
+		{2, 1, 0},
+		{0, -1, 2},
+		{2, -1, 1},
+	}
+	base := newHostsModel(t)
+	for _, tc := range cases {
+		m := base
+		m.selectTab(tc.from)
+		m.cycleTab(tc.delta)
+		if m.tab != tc.want {
+			t.Errorf("cycleTab(%d) from %d = %d, want %d", tc.delta, tc.from, m.tab, tc.want)
+		}
+	}
+}
+
+func TestRecordListing(t *testing.T) {
+	m := newHostsModel(t)
+	if got := m.tabs[1].entries[0].Host; got != "build" {
+		t.Errorf("remote entry Host = %q, want %q", got, "build")
+	}
+	if got := m.tabs[0].entries[0].Host; got != "" {
+		t.Errorf("local entry Host = %q, want none", got)
+	}
+
+	// A failed listing keeps the previous ports and reports the error only
+	// where the host is shown.
+	m.recordListing(portsLoadedMsg{host: 1, err: errors.New("ssh: connection refused")})
+	if len(m.tabs[1].entries) != 1 || m.tabs[1].err == nil {
+		t.Fatalf("after a failed listing: %d entries, err %v; want the old entry and the error", len(m.tabs[1].entries), m.tabs[1].err)
+	}
+	if m.errMsg != "" {
+		t.Errorf("errMsg = %q while the failed host is not shown", m.errMsg)
+	}
+	m.selectTab(1)
+	if !strings.Contains(m.errMsg, "build: ssh: connection refused") {
+		t.Errorf("errMsg = %q on the failed host's tab", m.errMsg)
+	}
+
+	m.recordListing(portsLoadedMsg{host: 1, entries: []ports.Port{}})
+	if m.tabs[1].err != nil || len(m.entries) != 0 || m.errMsg != "" {
+		t.Errorf("after a good listing: err %v, %d entries, errMsg %q; want a clean empty tab", m.tabs[1].err, len(m.entries), m.errMsg)
+	}
+}
+
+func TestRefreshTickSkipsHostStillListing(t *testing.T) {
+	m := newHostsModel(t)
+	if m.tabs[1].listing {
+		t.Fatal("host still marked as listing after its ports arrived")
+	}
+
+	next, cmd := m.Update(refreshTickMsg{host: 1})
+	m = next.(Model)
+	if cmd == nil || !m.tabs[1].listing {
+		t.Fatalf("refresh tick on an idle host: cmd %v, listing %v; want a reload", cmd != nil, m.tabs[1].listing)
+	}
+	loaded, ok := cmd().(portsLoadedMsg)
+	if !ok || loaded.host != 1 {
+		t.Fatalf("refresh tick ran %#v, want a listing of host 1", loaded)
+	}
+
+	if _, again := m.Update(refreshTickMsg{host: 1}); again != nil {
+		t.Error("refresh tick started a second listing while the first was outstanding")
+	}
+
+	m = update(t, m, loaded)
+	if m.tabs[1].listing {
+		t.Error("host still marked as listing after its ports arrived")
+	}
+	if _, cmd := m.Update(refreshTickMsg{host: 1}); cmd == nil {
+		t.Error("refresh tick after the listing arrived did not reload")
+	}
+}
+
+func TestFailedProbeKeepsFingerprint(t *testing.T) {
+	entry := ports.Port{PID: 999020, Process: "sshd", User: "root", Protocol: "tcp", Port: 2222, Address: "127.0.0.1", State: "LISTEN",
+		AppProtocol: ports.ProtoSSH}
+	m := newTestModel(t, entry)
+
+	m = update(t, m, probeResultMsg{entry: entry, err: errors.New("connection reset by peer")})
+	if got := m.entries[0].AppProtocol; got != ports.ProtoSSH {
+		t.Errorf("after a failed probe AppProtocol = %q, want %q", got, ports.ProtoSSH)
+	}
+	m = update(t, m, portsLoadedMsg{entries: []ports.Port{entry}})
+	if got := m.entries[0].AppProtocol; got != ports.ProtoSSH {
+		t.Errorf("after relisting AppProtocol = %q, want %q", got, ports.ProtoSSH)
+	}
+}
+
+func TestYankTargetsOmitURLOutsideNamespace(t *testing.T) {
+	keys := newKeyMap(config.Default().Keys)
+	host := ports.Port{PID: 10, Process: "node", Protocol: "tcp", Port: 8080, Address: "*", State: "LISTEN"}
+	boxed := host
+	boxed.Container, boxed.NetNS = "docker:3f2a1b9c4d5e", "net:[4026532451]"
+
+	cases := []struct {
+		entry ports.Port
+		url   string
+	}{
+		{host, "http://localhost:8080"},
+		{boxed, ""},
+	}
+	for _, tc := range cases {
+		url := ""
+		for _, target := range yankTargets(tc.entry, keys, "TERM") {
+			if target.binding.Help().Desc == keys.YankURL.Help().Desc {
+				url = target.value
+			}
+		}
+		if url != tc.url {
+			t.Errorf("yank URL for %s = %q, want %q", orDash(tc.entry.Container), url, tc.url)
+		}
+	}
+}
+
+func TestColumnLayoutFit(t *testing.T) {
+	configured := []string{"port", "process", "pid", "user", "address", "cmdline"}
+	cases := []struct {
+		width int
+		want  []string
+	}{
+		{200, configured},
+		{90, []string{"port", "process", "pid", "user", "address"}},
+		{60, []string{"port", "process", "pid", "user"}},
+		{5, []string{"port"}},
+	}
+	for _, tc := range cases {
+		layout := newColumnLayout(configured, nil)
+		layout.fit(tc.width)
+
+		var names []string
+		used := lipgloss.Width(rowPrefix) + 1 + lipgloss.Width(columnSeparator)*(len(layout.widths)-1)
+		for i, col := range layout.visible {
+			names = append(names, col.name)
+			used += layout.widths[i]
+			if layout.widths[i] < min(col.min, tc.width) && len(layout.visible) > 1 {
+				t.Errorf("fit(%d): %s is %d wide, below its minimum %d", tc.width, col.name, layout.widths[i], col.min)
+			}
+		}
+		if strings.Join(names, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("fit(%d) kept %v, want %v", tc.width, names, tc.want)
+		}
+		if used != tc.width {
+			t.Errorf("fit(%d) fills %d cells, want all of them", tc.width, used)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"math"
+	"strings"
+
+	"portkiller/internal/ports"
+
+	list "github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// updateMouse handles clicks and wheel events. Hit testing mirrors the layout
+// produced by View, so it must be kept in step with it.
+func (m Model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.queryEditing || m.helpVisible || m.yank != nil || m.history != nil {
+		return m, nil
+	}
+
+	if m.confirm != nil {
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			switch m.modalButtonAt(msg.X, msg.Y) {
+			case "confirm":
+				if m.killDenied {
+					return m, m.retryWithSudo()
+				}
+				return m, m.confirmKill()
+			case "cancel":
+				m.cancelKill()
+			case "stop_unit":
+				return m, m.unitAction(ports.UnitStop)
+			case "restart_unit":
+				return m, m.unitAction(ports.UnitRestart)
+			}
+		}
+		return m, nil
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.list.CursorUp()
+		return m, nil
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.list.CursorDown()
+		return m, nil
+	case msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft:
+		return m, nil
+	}
+
+	headerHeight := lipgloss.Height(m.renderHeader())
+	if m.multiHost() && msg.Y == headerHeight-hostTabLines {
+		m.selectTab(m.hostTabAt(msg.X))
+		return m, nil
+	}
+	if msg.Y == headerHeight {
+		m.sortBy(m.layout.columnAt(msg.X))
+		return m, nil
+	}
+
+	row := msg.Y - headerHeight - tableHeaderLines - m.listTitleHeight()
+	if row < 0 || row >= m.list.Paginator.PerPage {
+		return m, nil
+	}
+	start, end := m.list.Paginator.GetSliceBounds(len(m.list.VisibleItems()))
+	if index := start + row; index < end {
+		m.list.Select(index)
+	}
+	return m, nil
+}
+
+// sortBy sorts the table by the visible column at index. Clicking the active
+// column again flips the direction.
+func (m *Model) sortBy(index int) {
+	if index < 0 || index >= len(m.layout.visible) {
+		return
+	}
+	name := m.layout.visible[index].name
+	if m.sortColumn == name {
+		m.sortDesc = !m.sortDesc
+	} else {
+		m.sortColumn, m.sortDesc = name, false
+	}
+
+	var selected *portItem
+	if item, ok := m.list.SelectedItem().(portItem); ok {
+		selected = &item
+	}
+	m.applyFilters()
+	if selected != nil {
+		m.selectEntry(selected.entry)
+	}
+
+	direction := "ascending"
+	if m.sortDesc {
+		direction = "descending"
+	}
+	m.statusMsg = "↕️ Sorted by " + name + " " + direction
+}
+
+// selectEntry moves the cursor back to entry after the items were rebuilt.
+func (m *Model) selectEntry(entry ports.Port) {
+	for i, item := range m.list.VisibleItems() {
+		if pi, ok := item.(portItem); ok && sameSocket(pi.entry, entry) {
+			m.list.Select(i)
+			return
+		}
+	}
+}
+
+// listTitleHeight is the height of the list's title area, which bubbles/list
+// always draws above the rows: the filter bar while typing a filter and an
+// empty line otherwise.
+func (m Model) listTitleHeight() int {
+	if m.list.FilterState() == list.Filtering {
+		return lipgloss.Height(m.list.Styles.TitleBar.Render(m.list.FilterInput.View()))
+	}
+	return 1
+}
+
+// modalButtonAt reports which kill modal button, if any, is drawn at x, y.
+func (m Model) modalButtonAt(x, y int) string {
+	modal := m.killModal()
+	left := centredOffset(m.width, lipgloss.Width(modal))
+	top := centredOffset(m.height, lipgloss.Height(modal))
+
+	lines := strings.Split(modal, "\n")
+	if y < top || y >= top+len(lines) {
+		return ""
+	}
+	line := ansi.Strip(lines[y-top])
+
+	state := m.killModalState()
+	confirm, cancel := killModalCaptions(m.keys, state)
+	stop, restart := unitModalCaptions(m.keys, state)
+	buttons := map[string]string{"confirm": confirm, "cancel": cancel, "stop_unit": stop, "restart_unit": restart}
+	for name, caption := range buttons {
+		idx := strings.Index(line, caption)
+		if caption == "" || idx < 0 {
+			continue
+		}
+		// Buttons are padded by one cell on each side.
+		start := left + lipgloss.Width(line[:idx]) - 1
+		end := start + lipgloss.Width(caption) + 2
+		if x >= start && x < end {
+			return name
+		}
+	}
+	return ""
+}
+
+// centredOffset matches where lipgloss.Place puts centred content of size
+// within total.
+func centredOffset(total, size int) int {
+	gap := total - size
+	if gap <= 0 {
+		return 0
+	}
+	return gap - int(math.Round(float64(gap)*0.5))
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"portkiller/internal/ports"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newConfirmInput(st styles) textinput.Model {
+	input := textinput.New()
+	input.Prompt = "🛡️ "
+	input.PromptStyle = st.filterPrompt
+	input.TextStyle = st.inputText
+	input.PlaceholderStyle = st.hint
+	input.CharLimit = 128
+	input.Width = 32
+	return input
+}
+
+// openKillModal shows the kill modal for entry. Protected processes that
+// allow killing get the name prompt focused.
+func (m *Model) openKillModal(entry ports.Port) tea.Cmd {
+	if entry.Hidden {
+		m.toast = m.newToast(fmt.Sprintf("🙈 The owner of port %d is hidden · run pzapp as root to kill it", entry.Port), toastError)
+		return nil
+	}
+	m.confirm = &entry
+	m.killPending = false
+	m.killRestart = false
+	m.statusMsg = fmt.Sprintf("💀🗡️ Target locked: %s (%d)", entry.Process, entry.PID)
+	m.resizeList()
+
+	m.confirmInput.Reset()
+	m.confirmInput.Blur()
+	switch entry.Protection {
+	case ports.ProtectConfirm:
+		m.confirmInput.Placeholder = entry.Process
+		m.statusMsg = fmt.Sprintf("🛡️ %s is protected · type its name to confirm", entry.Process)
+		return m.confirmInput.Focus()
+	case ports.ProtectRefuse:
+		m.statusMsg = fmt.Sprintf("⛔ %s is protected (%s)", entry.Process, entry.ProtectedBy)
+	}
+	return nil
+}
+
+// killAllowed reports whether the protection rules let the modal's target
+// be killed now, explaining why not in a toast.
+func (m *Model) killAllowed() bool {
+	entry := m.confirm
+	switch entry.Protection {
+	case ports.ProtectRefuse:
+		m.toast = m.newToast(fmt.Sprintf("⛔ Refusing to kill %s: protected by %s", entry.Process, entry.ProtectedBy), toastError)
+		return false
+	case ports.ProtectConfirm:
+		if strings.TrimSpace(m.confirmInput.Value()) != entry.Process {
+			m.toast = m.newToast(fmt.Sprintf("🛡️ Type %q to confirm", entry.Process), toastError)
+			return false
+		}
+	}
+	return true
+}
+
+// updateGuardedKill routes key presses to the name prompt of a protected
+// kill modal. Enter confirms and esc cancels, since every other key is text.
+func (m Model) updateGuardedKill(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		return m, m.confirmKill()
+	case "esc":
+		m.cancelKill()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.confirmInput, cmd = m.confirmInput.Update(msg)
+	return m, cmd
+}
+
+// killModalState is what the kill modal shows for its target.
+type killModalState struct {
+	entry ports.Port
+	// inFlight is set while the kill runs, denied after it failed with a
+	// permission error and restart when confirming relaunches the process.
+	inFlight bool
+	denied   bool
+	restart  bool
+	// prompt is the rendered name input of a protected process.
+	prompt string
+}
+
+// killModalState describes the kill modal for the current target.
+func (m Model) killModalState() killModalState {
+	state := killModalState{entry: *m.confirm, inFlight: m.killPending, denied: m.killDenied, restart: m.killRestart}
+	if m.confirm.Protection == ports.ProtectConfirm && !m.killDenied {
+		state.prompt = m.confirmInput.View()
+	}
+	return state
+}
+
+// killModal renders the kill modal for the current target.
+func (m Model) killModal() string {
+	return renderKillModal(m.killModalState(), m.keys, m.styles, m.width)
+}
@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"portkiller/internal/config"
+	"portkiller/internal/killer"
+	"portkiller/internal/ports"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// restartSnapshotTimeout bounds reading the process's details.
+	restartSnapshotTimeout = time.Second
+	// restartFreeTimeout bounds waiting for a killed process's port to free,
+	// once the kill and its escalation are done.
+	restartFreeTimeout = 10 * time.Second
+	// restartUpTimeout bounds waiting for the relaunched process to listen.
+	restartUpTimeout = 30 * time.Second
+)
+
+// A restart runs as a chain of commands, each reporting back so the toast
+// can follow along: stop the process and wait for the port to free, launch
+// the captured command, then wait for the port to answer again.
+
+type restartStoppedMsg struct {
+	entry  ports.Port
+	snap   ports.ProcessSnapshot
+	result killer.Result
+}
+
+type restartLaunchedMsg struct {
+	entry ports.Port
+	pid   int
+	log   string
+	err   error
+}
+
+type restartDoneMsg struct {
+	entry ports.Port
+	pid   int
+	log   string
+	err   error
+}
+
+// openRestartModal shows the kill modal for entry with a restart confirm
+// button, so protection rules apply as they do to kills.
+func (m *Model) openRestartModal(entry ports.Port) tea.Cmd {
+	if entry.Host != "" {
+		// The command line is relaunched on this machine.
+		m.toast = m.newToast(fmt.Sprintf("🌐 %s runs on %s · restart only works on this machine", entry.Process, entry.Host), toastError)
+		return nil
+	}
+	if !entry.Reachable() {
+		// It would be relaunched outside its container, and its port waited
+		// for on the wrong network.
+		m.toast = m.newToast(fmt.Sprintf("📦 %s runs in %s · restart it with its container's tooling", entry.Process, entry.Container), toastError)
+		return nil
+	}
+	cmd := m.openKillModal(entry)
+	if m.confirm != nil {
+		m.killRestart = true
+		if entry.Protection == "" {
+			m.statusMsg = fmt.Sprintf("🔁 Restart %s (%d)?", entry.Process, entry.PID)
+		}
+	}
+	return cmd
+}
+
+// startRestart closes the modal and begins restarting the modal's target.
+func (m *Model) startRestart() tea.Cmd {
+	entry := *m.confirm
+	m.cancelKill()
+	m.toast = m.newToast(fmt.Sprintf("🔁 Restarting %s · stopping PID %d...", entry.Process, entry.PID), toastInfo)
+	return restartStopCmd(entry, m.killer)
+}
+
+// restartStopCmd captures entry's command line, working directory and
+// environment, kills it with the configured escalation and waits for its
+// port to free. The whole environment is kept, since it only lives in
+// memory until the relaunch.
+func restartStopCmd(entry ports.Port, k *killer.Killer) tea.Cmd {
+	return func() tea.Msg {
+		// Without /proc the listed command line is the fallback, as it is
+		// for the kill history.
+		ctx, cancel := context.WithTimeout(context.Background(), restartSnapshotTimeout)
+		snap, _ := ports.SnapshotProcess(ctx, entry.PID, []string{"*"})
+		cancel()
+		if len(snap.Argv) == 0 {
+			snap.Argv = strings.Fields(entry.Cmdline)
+		}
+		if len(snap.Argv) == 0 {
+			err := fmt.Errorf("the command line of %s (PID %d) is unknown", entry.Process, entry.PID)
+			return restartStoppedMsg{entry: entry, result: killer.Result{Err: err}}
+		}
+
+		// The kill's grace period and escalation take their own time; the
+		// port gets its full timeout afterwards.
+		result := k.Kill(entry, true)
+		if result.Err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), restartFreeTimeout)
+			defer cancel()
+			result.Err = ports.WaitPort(ctx, entry, false)
+		}
+		return restartStoppedMsg{entry: entry, snap: snap, result: result}
+	}
+}
+
+// restartLaunchCmd starts the captured command again, logging its output
+// alongside history relaunches.
+func restartLaunchCmd(entry ports.Port, snap ports.ProcessSnapshot, cfg config.Config) tea.Cmd {
+	return func() tea.Msg {
+		logPath := relaunchLogPath(cfg, entry.Process)
+		pid, err := ports.Launch(snap, logPath)
+		return restartLaunchedMsg{entry: entry, pid: pid, log: logPath, err: err}
+	}
+}
+
+// restartWaitCmd waits for the relaunched process to listen on the port.
+func restartWaitCmd(msg restartLaunchedMsg) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), restartUpTimeout)
+		defer cancel()
+		err := ports.WaitPort(ctx, msg.entry, true)
+		return restartDoneMsg{entry: msg.entry, pid: msg.pid, log: msg.log, err: err}
+	}
+}
+
+// updateRestart advances a restart with the outcome of its last step.
+func (m *Model) updateRestart(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case restartStoppedMsg:
+		if msg.result.HistoryErr != nil {
+			m.errMsg = fmt.Sprintf("recording kill history: %v", msg.result.HistoryErr)
+		}
+		if msg.result.AuditErr != nil {
+			m.errMsg = fmt.Sprintf("writing audit log: %v", msg.result.AuditErr)
+		}
+		if msg.result.Err != nil {
+			m.toast = m.newToast(fmt.Sprintf("⚠️ Restart of %s failed while stopping it", msg.entry.Process), toastError)
+			m.errMsg = fmt.Sprintf("restart failed: %v", msg.result.Err)
+			return m.loadPortsCmd()
+		}
+		m.removeEntry(msg.entry)
+		m.toast = m.newToast(fmt.Sprintf("🔁 Restarting %s · :%d is free, relaunching...", msg.entry.Process, msg.entry.Port), toastInfo)
+		return restartLaunchCmd(msg.entry, msg.snap, m.config)
+
+	case restartLaunchedMsg:
+		if msg.err != nil {
+			m.toast = m.newToast(fmt.Sprintf("⚠️ Could not relaunch %s", msg.entry.Process), toastError)
+			m.errMsg = fmt.Sprintf("restart failed: %v", msg.err)
+			return nil
+		}
+		m.toast = m.newToast(fmt.Sprintf("🔁 Restarting %s · PID %d started, waiting for :%d...", msg.entry.Process, msg.pid, msg.entry.Port), toastInfo)
+		return restartWaitCmd(msg)
+
+	case restartDoneMsg:
+		if msg.log != "" {
+			m.statusMsg = "📜 Output: " + displayPath(msg.log)
+		}
+		if msg.err != nil {
+			m.toast = m.newToast(fmt.Sprintf("⚠️ %s (PID %d) is not listening on :%d yet", msg.entry.Process, msg.pid, msg.entry.Port), toastError)
+			m.errMsg = fmt.Sprintf("restart: %v", msg.err)
+		} else {
+			m.toast = m.newToast(fmt.Sprintf("✅ %s is back on :%d as PID %d", msg.entry.Process, msg.entry.Port, msg.pid), toastSuccess)
+		}
+		return m.loadPortsCmd()
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"portkiller/internal/history"
+	"portkiller/internal/ports"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type sudoKillResultMsg struct {
+	entry ports.Port
+	err   error
+}
+
+// WithSudoKill returns the model offering to retry kills that fail with a
+// permission error as `sudo <helper...> --pid N`. helper is the pzapp
+// executable with its global flags, `kill` and any kill flags; without it
+// the retry is not offered.
+func (m Model) WithSudoKill(helper []string) Model {
+	m.sudoKill = helper
+	return m
+}
+
+// sudoKillArgs returns the sudo arguments that kill entry with the current
+// kill policy. The name prompt of a confirm-protected process was already
+// answered in the modal, so the helper is told not to ask again.
+func sudoKillArgs(helper []string, entry ports.Port, signal string) []string {
+	args := append(append([]string(nil), helper...), "--pid", strconv.Itoa(entry.PID), "--signal", strings.ToUpper(signal))
+	if entry.Protection == ports.ProtectConfirm {
+		args = append(args, "--force")
+	}
+	return args
+}
+
+// retryWithSudo suspends the TUI and runs the kill helper under sudo, so
+// its password prompt reaches the terminal.
+func (m *Model) retryWithSudo() tea.Cmd {
+	if m.confirm == nil || !m.killDenied {
+		return nil
+	}
+	entry := *m.confirm
+	m.confirm = nil
+	m.killDenied = false
+	m.resizeList()
+	m.statusMsg = fmt.Sprintf("🔓 Retrying %s (%d) with sudo...", entry.Process, entry.PID)
+	// The helper appends to the user's history; created by root, the file
+	// would no longer be readable here.
+	if k := m.killer; k != nil && k.History.Enabled {
+		if err := history.Touch(k.HistoryPath); err != nil {
+			m.errMsg = fmt.Sprintf("kill history: %v", err)
+		}
+	}
+
+	cmd := exec.Command("sudo", sudoKillArgs(m.sudoKill, entry, m.config.Kill.Policy().Signal)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return sudoKillResultMsg{entry: entry, err: err}
+	})
+}
+
+// updateDeniedKill handles keys while the kill modal reports a permission
+// error.
+func (m Model) updateDeniedKill(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Sudo):
+		return m, m.retryWithSudo()
+	case key.Matches(msg, m.keys.Cancel):
+		m.cancelKill()
+	}
+	return m, nil
+}
+
+// recordSudoKill reports the outcome of a sudo retry. The helper records
+// the kill history and audit log itself.
+func (m *Model) recordSudoKill(msg sudoKillResultMsg) tea.Cmd {
+	if msg.err != nil {
+		m.toast = m.newToast(fmt.Sprintf("⚠️ sudo kill of %s (%d) failed", msg.entry.Process, msg.entry.PID), toastError)
+		m.errMsg = fmt.Sprintf("sudo kill failed: %v", msg.err)
+		return nil
+	}
+	m.removeEntry(msg.entry)
+	m.toast = m.newToast(fmt.Sprintf("✅ Terminated %s (%d) with sudo", msg.entry.Process, msg.entry.PID), toastSuccess)
+	m.statusMsg = "🔄 Refreshing port list..."
+	return m.loadPortsCmd()
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"portkiller/internal/ports"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type unitResultMsg struct {
+	entry  ports.Port
+	action string
+	err    error
+}
+
+// unitModalCaptions returns the stop and restart unit button labels, or
+// empty ones when the modal's target has no unit to act on. The name prompt
+// of protected processes takes every key, so their buttons are click-only.
+func unitModalCaptions(keys keyMap, state killModalState) (string, string) {
+	entry := state.entry
+	if entry.Unit == "" || entry.Protection == ports.ProtectRefuse || state.denied || state.inFlight {
+		return "", ""
+	}
+	if entry.Protection == ports.ProtectConfirm {
+		return "⏹️  STOP UNIT", "🔁 RESTART UNIT"
+	}
+	return fmt.Sprintf("⏹️  [%s] STOP UNIT", strings.ToUpper(primaryKey(keys.StopUnit))),
+		fmt.Sprintf("🔁 [%s] RESTART UNIT", strings.ToUpper(primaryKey(keys.RestartUnit)))
+}
+
+// unitAction closes the kill modal and runs `systemctl stop|restart` on the
+// target's unit instead of signalling it, which systemd would answer by
+// restarting the service. The TUI is suspended so polkit can ask for a
+// password.
+func (m *Model) unitAction(action string) tea.Cmd {
+	if m.confirm == nil || m.confirm.Unit == "" || m.killPending || m.killDenied || !m.killAllowed() {
+		return nil
+	}
+	entry := *m.confirm
+	m.cancelKill()
+	cmd, err := ports.UnitCommand(context.Background(), entry, action)
+	if err != nil {
+		m.toast = m.newToast(fmt.Sprintf("⚠️ Cannot %s %s", action, unitLabel(entry)), toastError)
+		m.errMsg = fmt.Sprintf("systemctl %s: %v", action, err)
+		return nil
+	}
+	verb := "Stopping"
+	if action == ports.UnitRestart {
+		verb = "Restarting"
+	}
+	m.statusMsg = fmt.Sprintf("⚙️ %s %s...", verb, unitLabel(entry))
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return unitResultMsg{entry: entry, action: action, err: err}
+	})
+}
+
+// recordUnitResult reports the outcome of a unit action and refreshes the
+// list, where a restarted service shows up with its new PID.
+func (m *Model) recordUnitResult(msg unitResultMsg) tea.Cmd {
+	if auditErr := m.killer.Audit.Record(msg.entry, "systemctl-"+msg.action, msg.err); auditErr != nil {
+		m.errMsg = fmt.Sprintf("writing audit log: %v", auditErr)
+	}
+	if msg.err != nil {
+		m.toast = m.newToast(fmt.Sprintf("⚠️ systemctl %s %s failed", msg.action, msg.entry.Unit), toastError)
+		m.errMsg = fmt.Sprintf("systemctl %s: %v", msg.action, msg.err)
+		return nil
+	}
+	if msg.action == ports.UnitStop {
+		m.removeEntry(msg.entry)
+		m.toast = m.newToast(fmt.Sprintf("⏹️ Stopped %s", unitLabel(msg.entry)), toastSuccess)
+	} else {
+		m.toast = m.newToast(fmt.Sprintf("🔁 Restarted %s", unitLabel(msg.entry)), toastSuccess)
+	}
+	m.statusMsg = "🔄 Refreshing port list..."
+	return m.loadPortsCmd()
+}
@@ -0,0 +1,349 @@
+package ui
+
+import (
+	"portkiller/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is a colour palette for the TUI. Colours are hex strings or ANSI
+// colour numbers understood by lipgloss.Color.
+type Theme struct {
+	Name string
+
+	// OnAccent is the text colour drawn on top of accent backgrounds.
+	OnAccent   string
+	Surface    string
+	Panel      string
+	Border     string
+	Text       string
+	TextMuted  string
+	TextSubtle string
+
+	// Accent is the primary highlight, Highlight the secondary one.
+	Accent    string
+	Highlight string
+	Success   string
+	Error     string
+	Warning   string
+
+	// Accents is the colour cycle used by the animated header.
+	Accents []string
+
+	// SelectionBar marks the selected row with a gutter bar so it stays
+	// visible when backgrounds are not rendered (e.g. NO_COLOR).
+	SelectionBar bool
+}
+
+const (
+	// Matrix/Cyberpunk Color Palette
+	matrixBlack        = "#000000"
+	matrixSurface      = "#1a1a2e"
+	matrixPanel        = "#16213e"
+	matrixBorder       = "#0f3460"
+	matrixText         = "#00ff41"
+	matrixTextDim      = "#00cc33"
+	matrixTextSubtle   = "#009933"
+	matrixAccentNeon   = "#00ffff"
+	matrixAccentPink   = "#ff007f"
+	matrixAccentPurple = "#9d4edd"
+	matrixAccentBlue   = "#0077be"
+	matrixAccentGreen  = "#39ff14"
+	matrixAccentRed    = "#ff073a"
+	matrixAccentGold   = "#ffd700"
+)
+
+const (
+	// TokyoNight Color Palette
+	tokyoBase         = "#1a1b26"
+	tokyoSurface      = "#1f2335"
+	tokyoSurfaceAlt   = "#24283b"
+	tokyoSurfaceLine  = "#2e3247"
+	tokyoText         = "#c0caf5"
+	tokyoMutedText    = "#a9b1d6"
+	tokyoSubtleText   = "#565f89"
+	tokyoAccentBlue   = "#7aa2f7"
+	tokyoAccentPurple = "#bb9af7"
+	tokyoAccentCyan   = "#7dcfff"
+	tokyoAccentGreen  = "#9ece6a"
+	tokyoAccentYellow = "#e0af68"
+	tokyoAccentRed    = "#f7768e"
+)
+
+var builtinThemes = map[string]Theme{
+	"matrix": {
+		Name:       "matrix",
+		OnAccent:   matrixBlack,
+		Surface:    matrixSurface,
+		Panel:      matrixPanel,
+		Border:     matrixBorder,
+		Text:       matrixText,
+		TextMuted:  matrixTextDim,
+		TextSubtle: matrixTextSubtle,
+		Accent:     matrixAccentNeon,
+		Highlight:  matrixAccentPink,
+		Success:    matrixAccentGreen,
+		Error:      matrixAccentRed,
+		Warning:    matrixAccentGold,
+		Accents: []string{
+			matrixAccentNeon,
+			matrixAccentPink,
+			matrixAccentPurple,
+			matrixAccentBlue,
+			matrixAccentGreen,
+			matrixAccentGold,
+		},
+	},
+	"tokyonight": {
+		Name:       "tokyonight",
+		OnAccent:   tokyoBase,
+		Surface:    tokyoSurface,
+		Panel:      tokyoSurfaceAlt,
+		Border:     tokyoSurfaceLine,
+		Text:       tokyoText,
+		TextMuted:  tokyoMutedText,
+		TextSubtle: tokyoSubtleText,
+		Accent:     tokyoAccentBlue,
+		Highlight:  tokyoAccentPurple,
+		Success:    tokyoAccentGreen,
+		Error:      tokyoAccentRed,
+		Warning:    tokyoAccentYellow,
+		Accents:    []string{tokyoAccentBlue, tokyoAccentPurple, tokyoAccentCyan, tokyoAccentGreen},
+	},
+	"high-contrast": {
+		Name:         "high-contrast",
+		OnAccent:     "#000000",
+		Surface:      "#000000",
+		Panel:        "#000000",
+		Border:       "#ffffff",
+		Text:         "#ffffff",
+		TextMuted:    "#ffffff",
+		TextSubtle:   "#d0d0d0",
+		Accent:       "#00ffff",
+		Highlight:    "#ffff00",
+		Success:      "#00ff00",
+		Error:        "#ff5555",
+		Warning:      "#ffff00",
+		Accents:      []string{"#00ffff", "#ffff00"},
+		SelectionBar: true,
+	},
+	"monochrome": {
+		Name:         "monochrome",
+		OnAccent:     "#000000",
+		Surface:      "",
+		Panel:        "",
+		Border:       "#808080",
+		Text:         "#d0d0d0",
+		TextMuted:    "#a8a8a8",
+		TextSubtle:   "#6c6c6c",
+		Accent:       "#ffffff",
+		Highlight:    "#bcbcbc",
+		Success:      "#ffffff",
+		Error:        "#ffffff",
+		Warning:      "#e4e4e4",
+		Accents:      []string{"#ffffff"},
+		SelectionBar: true,
+	},
+}
+
+// resolveTheme returns the named built-in theme or a user palette layered on
+// top of its base theme. Unknown names fall back to matrix; config validation
+// rejects them before the TUI starts.
+func resolveTheme(name string, custom map[string]config.Palette) Theme {
+	if theme, ok := builtinThemes[name]; ok {
+		return theme
+	}
+
+	palette, ok := custom[name]
+	if !ok {
+		return builtinThemes["matrix"]
+	}
+
+	theme, ok := builtinThemes[palette.Base]
+	if !ok {
+		theme = builtinThemes["matrix"]
+	}
+	theme.Name = name
+	override := func(dst *string, value string) {
+		if value != "" {
+			*dst = value
+		}
+	}
+	override(&theme.OnAccent, palette.OnAccent)
+	override(&theme.Surface, palette.Surface)
+	override(&theme.Panel, palette.Panel)
+	override(&theme.Border, palette.Border)
+	override(&theme.Text, palette.Text)
+	override(&theme.TextMuted, palette.TextMuted)
+	override(&theme.TextSubtle, palette.TextSubtle)
+	override(&theme.Accent, palette.Accent)
+	override(&theme.Highlight, palette.Highlight)
+	override(&theme.Success, palette.Success)
+	override(&theme.Error, palette.Error)
+	override(&theme.Warning, palette.Warning)
+	if len(palette.Accents) > 0 {
+		theme.Accents = append([]string(nil), palette.Accents...)
+	}
+	return theme
+}
+
+// styles are the lipgloss styles derived from a Theme.
+type styles struct {
+	listTitle      lipgloss.Style
+	listDesc       lipgloss.Style
+	selectedTitle  lipgloss.Style
+	selectedDesc   lipgloss.Style
+	tableHeader    lipgloss.Style
+	tableSeparator lipgloss.Style
+	filterBar      lipgloss.Style
+	filterPrompt   lipgloss.Style
+	filterCursor   lipgloss.Style
+	headerTagline  lipgloss.Style
+	headerSubtitle lipgloss.Style
+	headerBorder   lipgloss.Style
+	status         lipgloss.Style
+	errorText      lipgloss.Style
+	hint           lipgloss.Style
+	dim            lipgloss.Style
+	inputText      lipgloss.Style
+	hostTab        lipgloss.Style
+	hostTabActive  lipgloss.Style
+
+	modal         lipgloss.Style
+	modalContent  lipgloss.Style
+	modalTitle    lipgloss.Style
+	modalSubtitle lipgloss.Style
+	modalStatus   lipgloss.Style
+	modalConfirm  lipgloss.Style
+	modalCancel   lipgloss.Style
+	modalSpacer   lipgloss.Style
+
+	help         lipgloss.Style
+	helpPanel    lipgloss.Style
+	helpHeader   lipgloss.Style
+	helpSection  lipgloss.Style
+	helpCommand  lipgloss.Style
+	helpKey      lipgloss.Style
+	helpDesc     lipgloss.Style
+	infoToast    lipgloss.Style
+	successToast lipgloss.Style
+	errorToast   lipgloss.Style
+}
+
+func newStyles(t Theme) styles {
+	color := func(c string) lipgloss.TerminalColor {
+		if c == "" {
+			return lipgloss.NoColor{}
+		}
+		return lipgloss.Color(c)
+	}
+
+	s := styles{}
+
+	s.listTitle = lipgloss.NewStyle().
+		Foreground(color(t.Text)).
+		Background(color(t.Surface)).
+		PaddingLeft(1)
+	s.listDesc = s.listTitle.
+		Foreground(color(t.TextMuted))
+
+	s.selectedTitle = lipgloss.NewStyle().
+		Foreground(color(t.OnAccent)).
+		Background(color(t.Accent)).
+		Bold(true).
+		PaddingLeft(1)
+	s.selectedDesc = lipgloss.NewStyle().
+		Foreground(color(t.OnAccent)).
+		Background(color(t.Highlight)).
+		PaddingLeft(1)
+	if t.SelectionBar {
+		s.selectedTitle = s.selectedTitle.
+			PaddingLeft(0).
+			Border(lipgloss.ThickBorder(), false, false, false, true).
+			BorderForeground(color(t.Accent))
+	}
+
+	s.tableHeader = lipgloss.NewStyle().
+		Background(color(t.Panel)).
+		Bold(true).
+		PaddingLeft(1)
+	s.tableSeparator = lipgloss.NewStyle().
+		Foreground(color(t.TextSubtle))
+
+	s.filterBar = lipgloss.NewStyle().
+		Background(color(t.Panel)).
+		Foreground(color(t.TextMuted)).
+		Padding(0, 1, 0, 1).
+		Border(lipgloss.DoubleBorder(), false, false, true, false).
+		BorderForeground(color(t.Accent))
+	s.filterPrompt = lipgloss.NewStyle().Foreground(color(t.Accent)).Bold(true)
+	s.filterCursor = lipgloss.NewStyle().Foreground(color(t.Accent))
+
+	s.headerTagline = lipgloss.NewStyle().
+		Foreground(color(t.Accent)).
+		Bold(true).
+		PaddingLeft(1)
+	s.headerSubtitle = lipgloss.NewStyle().
+		Foreground(color(t.TextMuted)).
+		PaddingLeft(1)
+	s.headerBorder = lipgloss.NewStyle().
+		Foreground(color(t.Border))
+
+	s.status = lipgloss.NewStyle().Foreground(color(t.TextMuted))
+	s.errorText = lipgloss.NewStyle().Foreground(color(t.Error)).Bold(true)
+	s.hint = lipgloss.NewStyle().Foreground(color(t.TextSubtle))
+	s.dim = lipgloss.NewStyle().Foreground(color(t.TextSubtle))
+	s.inputText = lipgloss.NewStyle().Foreground(color(t.Text))
+	s.hostTab = lipgloss.NewStyle().Foreground(color(t.TextMuted))
+	s.hostTabActive = lipgloss.NewStyle().Foreground(color(t.OnAccent)).Bold(true)
+
+	s.modal = lipgloss.NewStyle().
+		Padding(1, 3).
+		Border(lipgloss.ThickBorder()).
+		BorderForeground(color(t.Highlight)).
+		Background(color(t.Surface))
+	s.modalContent = lipgloss.NewStyle().
+		Foreground(color(t.Text)).
+		Background(color(t.Surface))
+	s.modalTitle = lipgloss.NewStyle().
+		Foreground(color(t.Highlight)).
+		Bold(true)
+	s.modalSubtitle = lipgloss.NewStyle().
+		Foreground(color(t.TextMuted))
+	s.modalStatus = lipgloss.NewStyle().
+		Foreground(color(t.Accent))
+	s.modalConfirm = lipgloss.NewStyle().
+		Foreground(color(t.OnAccent)).
+		Background(color(t.Success)).
+		Bold(true).
+		Padding(0, 1)
+	s.modalCancel = lipgloss.NewStyle().
+		Foreground(color(t.TextMuted)).
+		Background(color(t.Panel)).
+		Padding(0, 1)
+	s.modalSpacer = lipgloss.NewStyle().
+		Foreground(color(t.TextSubtle))
+
+	s.help = lipgloss.NewStyle().Foreground(color(t.TextMuted))
+	s.helpPanel = lipgloss.NewStyle().
+		Padding(1, 3).
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(color(t.Border)).
+		Background(color(t.Surface)).
+		Width(76)
+	s.helpHeader = lipgloss.NewStyle().Foreground(color(t.Warning))
+	s.helpSection = lipgloss.NewStyle().
+		Foreground(color(t.Accent)).
+		Bold(true)
+	s.helpCommand = lipgloss.NewStyle().Foreground(color(t.Text))
+	s.helpKey = lipgloss.NewStyle().
+		Foreground(color(t.Highlight)).
+		Bold(true)
+	s.helpDesc = lipgloss.NewStyle().Foreground(color(t.TextMuted))
+
+	s.infoToast = lipgloss.NewStyle().Foreground(color(t.Accent))
+	s.successToast = lipgloss.NewStyle().Foreground(color(t.Success))
+	s.errorToast = lipgloss.NewStyle().Foreground(color(t.Error))
+
+	return s
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"portkiller/internal/clipboard"
+	"portkiller/internal/ports"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// yankTarget is one entry of the yank menu.
+type yankTarget struct {
+	binding key.Binding
+	value   string
+}
+
+// clipboardHold is how long the OSC52 sequence stays in the rendered
+// frames; it must outlast at least one frame of the renderer.
+const clipboardHold = 100 * time.Millisecond
+
+type clipboardMsg struct {
+	label string
+	value string
+}
+
+// clipboardSentMsg drops the OSC52 sequence from the view once it has been
+// rendered.
+type clipboardSentMsg struct {
+	id int
+}
+
+// yankTargets lists what can be copied for entry. Targets without a value,
+// such as an unknown command line, are left out.
+func yankTargets(entry ports.Port, keys keyMap, signal string) []yankTarget {
+	targets := []yankTarget{
+		{keys.YankPID, strconv.Itoa(entry.PID)},
+		{keys.YankPort, strconv.Itoa(entry.Port)},
+		{keys.YankAddress, hostPort(entry)},
+		{keys.YankURL, browseURL(entry)},
+		{keys.YankCmdline, entry.Cmdline},
+		{keys.YankKill, fmt.Sprintf("kill -%s %d", strings.ToUpper(signal), entry.PID)},
+	}
+	if entry.Hidden {
+		// Without a PID there is nothing to signal.
+		targets[0].value, targets[5].value = "", ""
+	}
+	available := targets[:0]
+	for _, target := range targets {
+		if target.value != "" {
+			available = append(available, target)
+		}
+	}
+	return available
+}
+
+// hostPort joins the listening address and port, bracketing IPv6 literals.
+func hostPort(entry ports.Port) string {
+	host := entry.Address
+	if host == "" {
+		host = "*"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(entry.Port))
+}
+
+// browseURL is the http URL for the port. Wildcard and loopback listeners are
+// reached through localhost, or the host name on remote hosts. Listeners in
+// another network namespace have no URL from here.
+func browseURL(entry ports.Port) string {
+	if !entry.Reachable() {
+		return ""
+	}
+	if entry.Host != "" {
+		return "http://" + net.JoinHostPort(entry.DialHost(), strconv.Itoa(entry.Port))
+	}
+	host := entry.Address
+	switch host {
+	case "", "*", "0.0.0.0", "::", "[::]":
+		host = "localhost"
+	default:
+		if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+			host = "localhost"
+		}
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(entry.Port))
+}
+
+// updateYankMenu handles a key press while the yank menu is open.
+func (m Model) updateYankMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Back) {
+		m.yank = nil
+		m.resizeList()
+		return m, nil
+	}
+
+	for _, target := range yankTargets(*m.yank, m.keys, m.config.Kill.Signal) {
+		if key.Matches(msg, target.binding) {
+			m.yank = nil
+			m.resizeList()
+			m.clipboardID++
+			m.clipboard = clipboard.Sequence(target.value)
+			id := m.clipboardID
+			return m, tea.Batch(
+				copyCmd(target.binding.Help().Desc, target.value),
+				tea.Tick(clipboardHold, func(time.Time) tea.Msg { return clipboardSentMsg{id: id} }),
+			)
+		}
+	}
+	return m, nil
+}
+
+// copyCmd also copies through the native clipboard. The OSC52 sequence goes
+// out with the view, so a missing clipboard tool is no failure; the command
+// never writes to the terminal itself, which would race the renderer.
+func copyCmd(label, value string) tea.Cmd {
+	return func() tea.Msg {
+		_ = clipboard.CopyNative(value)
+		return clipboardMsg{label: label, value: value}
+	}
+}
+
+func renderYankMenu(entry ports.Port, keys keyMap, signal string, st styles) string {
+	title := st.modalTitle.Render(fmt.Sprintf("📋 YANK 【 %s | %s:%d | PID:%d 】",
+		entry.Process, strings.ToUpper(entry.Protocol), entry.Port, entry.PID))
+
+	rows := []string{title, ""}
+	for _, target := range yankTargets(entry, keys, signal) {
+		rows = append(rows, fmt.Sprintf("%s  %s %s",
+			st.helpKey.Render(padded(primaryKey(target.binding), 3)),
+			st.helpCommand.Render(padded(target.binding.Help().Desc, 13)),
+			st.helpDesc.Render(padded(target.value, 48))))
+	}
+	rows = append(rows, "", st.modalSubtitle.Render(fmt.Sprintf("%s  close", primaryKey(keys.Back))))
+
+	content := st.modalContent.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+	return st.modal.Render(content)
+}